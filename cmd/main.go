@@ -1,6 +1,12 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"example.com/main/internal/cli"
 	"example.com/main/internal/controller"
 	"example.com/main/internal/model"
 	"example.com/main/internal/view"
@@ -10,22 +16,120 @@ import (
 	"github.com/rivo/tview"
 )
 
+// Version is set at build time with -ldflags "-X main.Version=...".
+var Version = "dev"
+
 func main() {
+	fs := flag.NewFlagSet("argocd-tui", flag.ExitOnError)
+	fs.String("server", "", "ArgoCD server URL (env "+config.EnvServer+")")
+	fs.String("context", "", "server profile from the config file (env "+config.EnvContext+")")
+	fs.String("config", "", "path to config.yaml (env "+config.EnvConfig+")")
+	fs.String("log-level", config.DefaultLogLevel, "log level: trace, debug, info, warn or error (env "+config.EnvLogLevel+")")
+	fs.String("log-file", config.DefaultLogFile, "file to write logs to (env "+config.EnvLogFile+")")
+	fs.Bool("insecure", false, "skip TLS certificate verification (env "+config.EnvInsecure+")")
+	fs.Bool("readonly", false, "refuse every command that changes the cluster (env "+config.EnvReadonly+")")
+	fs.String("app", "", "application to select on startup (env "+config.EnvApp+")")
+	version := fs.Bool("version", false, "print the version and exit")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: argocd-tui [flags] [apps <command>]\n\n")
+		fmt.Fprintf(fs.Output(), "Without a command the terminal UI is started. Run 'argocd-tui apps help' for the headless commands.\n\n")
+		fmt.Fprintf(fs.Output(), "Flags take precedence over environment variables, which take precedence over the config file.\n\n")
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(os.Args[1:])
+
+	// global flags may also follow the subcommand, as in apps list --config x
+	global, args := cli.SplitFlags(fs, fs.Args())
+	fs.Parse(global)
+
+	if *version {
+		fmt.Println(Version)
+		return
+	}
+
+	flags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+
+	// checking only reads the config file, a missing one is a problem
+	if len(args) > 0 && args[0] == "config" {
+		check := cli.NewCLI(nil, os.Stdout, os.Stderr)
+		check.ConfigPath = config.ResolvePath(flags)
+		if check.ConfigPath == "" {
+			check.ConfigPath = config.DefaultPath()
+		}
+
+		cfg, err := config.Load(check.ConfigPath)
+		switch {
+		case os.IsNotExist(err):
+			check.ConfigProblems = []config.Problem{{Message: "the file does not exist"}}
+		case err != nil:
+			check.ConfigProblems = []config.Problem{{Message: err.Error()}}
+		default:
+			check.ConfigProblems = append(cfg.Problems, controller.CheckBindings(cfg)...)
+			config.SortProblems(check.ConfigProblems)
+		}
+		os.Exit(check.Run(args))
+	}
+
+	cfg := config.NewConfig(config.ResolvePath(flags))
+
+	opts, err := config.ResolveOptions(flags, cfg)
+	if err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+
+	l := logger.SetupLogger(opts.LogLevel, opts.LogFile)
+
+	newService := func() *argocd.Service {
+		if opts.Server == "" {
+			log.Fatalf("No ArgoCD server configured, use --server, %s or a context in %s", config.EnvServer, cfg.Path)
+		}
+		svc := argocd.NewService(l, opts.Server, opts.Insecure)
+		svc.Readonly = opts.Readonly
+		svc.SetCacheLimits(cfg.CacheTTL, cfg.CacheSize)
+		svc.ListOptions = cfg.ListOptions
+		return svc
+	}
+
+	if len(args) > 0 {
+		headless := cli.NewCLI(newService, os.Stdout, os.Stderr)
+		headless.Stdin = os.Stdin
+		headless.Readonly = opts.Readonly
+		headless.Protected = opts.Protected
+		os.Exit(headless.Run(args))
+	}
+
 	app := tview.NewApplication()
-	l := logger.SetupLogger()
-	argocdSvc := argocd.NewService(l)
+	argocdSvc := newService()
+	argocdSvc.ListOptions.Fields = controller.SummaryFields(cfg.Columns)
 	appModel := model.NewAppModel(l, argocdSvc)
-	commandModel := model.NewCommandModel()
-	config := config.NewConfig()
-	appView := view.NewAppView(app, config, l)
+	appModel.InitialApp = opts.App
+	appModel.ContextName = opts.Context
+	if appModel.ContextName == "" {
+		appModel.ContextName = opts.Server
+	}
+	appModel.Protected = opts.Protected
+	commandModel := model.NewCommandModel(cfg.Keys)
+	commandModel.Readonly = opts.Readonly
+	appView := view.NewAppView(app, cfg, l)
 	appController := controller.NewAppController(
 		appModel,
 		commandModel,
 		appView,
 	)
 
-	err := appController.Start()
+	appController.State, err = config.LoadState(config.DefaultStatePath())
+	if err != nil {
+		l.Warnf("Could not load the saved state: %v", err)
+	}
+
+	err = appController.Start()
 	if err != nil {
-		l.Fatalf("Could not start controller: %v", err)
+		l.Errorf("Could not start controller: %v", err)
+		log.Fatalf("Could not start controller: %v", err)
 	}
 }
@@ -0,0 +1,478 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/main/services/argocd"
+	"example.com/main/services/config"
+	"example.com/main/services/images"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK        = 0
+	ExitError     = 1
+	ExitUsage     = 2
+	ExitUnhealthy = 3
+	ExitTimeout   = 4
+	ExitRefused   = 5
+)
+
+const usage = `Usage: argocd-tui apps <command> [flags]
+       argocd-tui config check
+
+Commands:
+  list                  List applications
+  get <name>            Show an application
+  tree <name>           Show the resource tree of an application
+  sync <name> [--wait]  Sync an application, optionally waiting until it settles
+  events <name>         Show the events of an application
+  images                Report the images running in every application
+  config check          Validate the config file
+
+Flags:
+  -o, --output string   Output format: table, json, yaml or csv (default "table")
+      --wait            Wait for a sync to finish and the application to settle
+      --timeout duration  How long --wait waits (default 5m0s)
+      --confirm string  Application name, required to change a protected server
+
+Global flags such as --config, --context and --server may also follow the
+command.
+
+Exit codes: 0 success, 1 error, 2 usage, 3 sync failed or application
+Degraded/Missing after --wait, 4 timed out waiting, 5 refused by the
+read-only or protected mode.
+`
+
+type CLI struct {
+	// Service is created lazily so usage errors do not require a login.
+	NewService func() *argocd.Service
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+	Readonly   bool
+	Protected  bool
+	ConfigPath string
+	// ConfigProblems are the problems found while loading the config file,
+	// reported by config check.
+	ConfigProblems []config.Problem
+	service        *argocd.Service
+}
+
+func NewCLI(newService func() *argocd.Service, stdout io.Writer, stderr io.Writer) *CLI {
+	return &CLI{
+		NewService: newService,
+		Stdout:     stdout,
+		Stderr:     stderr,
+	}
+}
+
+func (c *CLI) Service() *argocd.Service {
+	if c.service == nil {
+		c.service = c.NewService()
+	}
+	return c.service
+}
+
+// Run executes the subcommand in args, for example ["apps", "get", "guestbook"],
+// and returns the process exit code.
+func (c *CLI) Run(args []string) int {
+	if len(args) == 2 && args[0] == "config" && args[1] == "check" {
+		return c.ConfigCheck()
+	}
+
+	if len(args) < 2 || args[0] != "apps" {
+		fmt.Fprint(c.Stderr, usage)
+		return ExitUsage
+	}
+
+	command := args[1]
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Fprint(c.Stdout, usage)
+		return ExitOK
+	}
+
+	fs := flag.NewFlagSet("apps "+command, flag.ContinueOnError)
+	fs.SetOutput(c.Stderr)
+	fs.Usage = func() { fmt.Fprint(c.Stderr, usage) }
+
+	output := fs.String("output", "table", "output format: table, json, yaml or csv")
+	fs.StringVar(output, "o", "table", "output format: table, json, yaml or csv")
+	wait := fs.Bool("wait", false, "wait for the sync to finish and the application to settle")
+	timeout := fs.Duration("timeout", 5*time.Minute, "how long --wait waits")
+	confirm := fs.String("confirm", "", "application name, required to change a protected server")
+
+	positional, err := parseInterspersed(fs, args[2:])
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		return ExitUsage
+	}
+
+	format := Format(strings.ToLower(*output))
+	if !format.Valid() {
+		fmt.Fprintf(c.Stderr, "error: unknown output format %q\n", *output)
+		return ExitUsage
+	}
+
+	switch {
+	case command == "list" || command == "images":
+		if len(positional) > 0 {
+			fmt.Fprintf(c.Stderr, "error: apps %s takes no arguments, got %q\n", command, positional)
+			return ExitUsage
+		}
+	case len(positional) != 1:
+		fmt.Fprintf(c.Stderr, "error: apps %s expects exactly one application name\n", command)
+		return ExitUsage
+	}
+
+	switch command {
+	case "list":
+		return c.List(format)
+	case "get":
+		return c.Get(positional[0], format)
+	case "tree":
+		return c.Tree(positional[0], format)
+	case "sync":
+		code := c.Guard("sync", positional[0], *confirm)
+		if code != ExitOK {
+			return code
+		}
+		return c.Sync(positional[0], format, *wait, *timeout)
+	case "events":
+		return c.Events(positional[0], format)
+	case "images":
+		return c.Images(format)
+	}
+
+	fmt.Fprintf(c.Stderr, "error: unknown command %q\n\n%s", command, usage)
+	return ExitUsage
+}
+
+// parseInterspersed parses flags that appear before or after positional
+// arguments, which the flag package alone does not allow.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// SplitFlags takes the flags defined in fs out of args, wherever they are,
+// so flags like --config and --server also work after a subcommand. The
+// remaining args are returned in order.
+func SplitFlags(fs *flag.FlagSet, args []string) ([]string, []string) {
+	flags, rest := []string{}, []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return flags, append(rest, args[i:]...)
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			rest = append(rest, arg)
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+
+		f := fs.Lookup(name)
+		if f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		flags = append(flags, arg)
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); hasValue || (ok && boolFlag.IsBoolFlag()) {
+			continue
+		}
+		if i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+
+	return flags, rest
+}
+
+func (c *CLI) ConfigCheck() int {
+	if len(c.ConfigProblems) == 0 {
+		fmt.Fprintf(c.Stdout, "%s: OK\n", c.ConfigPath)
+		return ExitOK
+	}
+
+	for _, problem := range c.ConfigProblems {
+		if problem.Line > 0 {
+			fmt.Fprintf(c.Stderr, "%s:%s\n", c.ConfigPath, problem)
+			continue
+		}
+		fmt.Fprintf(c.Stderr, "%s: %s\n", c.ConfigPath, problem)
+	}
+
+	return ExitError
+}
+
+// Guard refuses changes in read-only mode. On protected servers the
+// application name has to be passed with --confirm or typed on stdin.
+func (c *CLI) Guard(action string, name string, confirm string) int {
+	if c.Readonly {
+		fmt.Fprintf(c.Stderr, "error: refusing to %s %s in read-only mode\n", action, name)
+		return ExitRefused
+	}
+
+	if !c.Protected || confirm == name {
+		return ExitOK
+	}
+
+	if confirm == "" && c.Stdin != nil {
+		fmt.Fprintf(c.Stderr, "This server is protected. Type %s to %s it: ", name, action)
+		confirm, _ = bufio.NewReader(c.Stdin).ReadString('\n')
+		confirm = strings.TrimSpace(confirm)
+	}
+
+	if confirm != name {
+		fmt.Fprintf(c.Stderr, "error: refusing to %s %s, the confirmation does not match\n", action, name)
+		return ExitRefused
+	}
+
+	return ExitOK
+}
+
+func (c *CLI) fail(err error) int {
+	fmt.Fprintf(c.Stderr, "error: %v\n", err)
+	return ExitError
+}
+
+func (c *CLI) List(format Format) int {
+	result, err := c.Service().ListApplicationsContext(context.Background())
+	if err != nil {
+		return c.fail(err)
+	}
+
+	rows := [][]string{}
+	for _, app := range result.Items {
+		rows = append(rows, applicationRow(app))
+	}
+
+	err = Write(c.Stdout, format, result.Items, applicationColumns, rows)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return ExitOK
+}
+
+func (c *CLI) Get(name string, format Format) int {
+	app, err := c.Service().GetApplication(name)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	err = Write(c.Stdout, format, app, applicationColumns, [][]string{applicationRow(*app)})
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return ExitOK
+}
+
+func (c *CLI) Tree(name string, format Format) int {
+	nodes, err := c.Service().GetResourceTreeContext(context.Background(), name)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	rows := [][]string{}
+	for _, node := range nodes {
+		rows = append(rows, []string{
+			node.Kind,
+			node.Namespace,
+			node.Name,
+			node.Health.Status,
+			strings.Join(node.Images, ","),
+		})
+	}
+
+	err = Write(c.Stdout, format, nodes, []string{"KIND", "NAMESPACE", "NAME", "HEALTH", "IMAGES"}, rows)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return ExitOK
+}
+
+func (c *CLI) Events(name string, format Format) int {
+	events, err := c.Service().ListEvents(name)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	rows := [][]string{}
+	for _, event := range events {
+		rows = append(rows, []string{
+			event.LastTimestamp.Local().Format(time.DateTime),
+			event.Type,
+			event.Reason,
+			fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name),
+			event.Message,
+		})
+	}
+
+	err = Write(c.Stdout, format, events, []string{"LAST SEEN", "TYPE", "REASON", "OBJECT", "MESSAGE"}, rows)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return ExitOK
+}
+
+// Images reports the images of every application and which of them run in
+// more than one version. The report is written even when some trees could
+// not be fetched, but the exit code tells.
+func (c *CLI) Images(format Format) int {
+	result, err := c.Service().ListApplicationsContext(context.Background())
+	if err != nil {
+		return c.fail(err)
+	}
+
+	requests := make([]argocd.TreeRequest, len(result.Items))
+	for i, app := range result.Items {
+		requests[i] = argocd.TreeRequest{Name: app.Metadata.Name, Version: app.Metadata.ResourceVersion}
+	}
+
+	var mu sync.Mutex
+	trees := map[string][]argocd.ApplicationNode{}
+	failed := 0
+
+	c.Service().FetchTrees(context.Background(), requests, func(request argocd.TreeRequest, nodes []argocd.ApplicationNode, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err != nil {
+			fmt.Fprintf(c.Stderr, "error: %s: %v\n", request.Name, err)
+			failed++
+			return
+		}
+		trees[request.Name] = nodes
+	})
+
+	report := images.Report(trees)
+
+	rows := [][]string{}
+	for _, usage := range report {
+		rows = append(rows, images.Row(usage))
+	}
+
+	err = Write(c.Stdout, format, report, images.Columns, rows)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if failed > 0 {
+		return ExitError
+	}
+	return ExitOK
+}
+
+func (c *CLI) Sync(name string, format Format, wait bool, timeout time.Duration) int {
+	app, err := c.Service().SyncApplication(name)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	code := ExitOK
+
+	if wait {
+		app, code = c.waitForSync(name, timeout)
+		if app == nil {
+			return code
+		}
+	}
+
+	err = Write(c.Stdout, format, app, applicationColumns, [][]string{applicationRow(*app)})
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return code
+}
+
+// waitForSync polls the application until its operation finished and its
+// health is no longer Progressing. It returns ExitUnhealthy when the
+// operation failed or the application ended up Degraded or Missing.
+func (c *CLI) waitForSync(name string, timeout time.Duration) (*argocd.ApplicationItem, int) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		app, err := c.Service().GetApplication(name)
+		if err != nil {
+			return nil, c.fail(err)
+		}
+
+		// the operation field is cleared once the controller finished it,
+		// until then operationState may still describe the previous sync
+		phase := argocd.OperationRunning
+		if app.Operation == nil && app.Status.OperationState != nil {
+			phase = app.Status.OperationState.Phase
+		}
+
+		switch {
+		case phase == argocd.OperationFailed || phase == argocd.OperationError:
+			fmt.Fprintf(c.Stderr, "sync %s: %s\n", strings.ToLower(string(phase)), app.Status.OperationState.Message)
+			return app, ExitUnhealthy
+		case phase == argocd.OperationSucceeded && app.Status.Health.Status != argocd.StatusProgressing:
+			switch app.Status.Health.Status {
+			case argocd.StatusDegraded, argocd.StatusMissing:
+				fmt.Fprintf(c.Stderr, "application %s is %s\n", name, app.Status.Health.Status)
+				return app, ExitUnhealthy
+			}
+			return app, ExitOK
+		}
+
+		if time.Now().After(deadline) {
+			fmt.Fprintf(c.Stderr, "error: timed out after %s waiting for %s\n", timeout, name)
+			return app, ExitTimeout
+		}
+
+		time.Sleep(2 * time.Second)
+	}
+}
+
+var applicationColumns = []string{"NAME", "PROJECT", "SYNC", "HEALTH", "OPERATION"}
+
+func applicationRow(app argocd.ApplicationItem) []string {
+	project := app.Project()
+
+	phase := ""
+	if app.Status.OperationState != nil {
+		phase = string(app.Status.OperationState.Phase)
+	}
+
+	return []string{
+		app.Metadata.Name,
+		project,
+		string(app.Status.Sync.Status),
+		string(app.Status.Health.Status),
+		phase,
+	}
+}
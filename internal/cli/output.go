@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatCSV   Format = "csv"
+)
+
+func (f Format) Valid() bool {
+	switch f {
+	case FormatTable, FormatJSON, FormatYAML, FormatCSV:
+		return true
+	}
+	return false
+}
+
+// Write prints value as JSON or YAML, or the rows as an aligned table or CSV.
+func Write(w io.Writer, format Format, value any, columns []string, rows [][]string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case FormatYAML:
+		// round trip through JSON so the YAML keys match the API field names
+		jsonBytes, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON: %w", err)
+		}
+
+		var generic any
+		err = yaml.Unmarshal(jsonBytes, &generic)
+		if err != nil {
+			return fmt.Errorf("error converting to YAML: %w", err)
+		}
+
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+		return encoder.Encode(generic)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		writer.Write(columns)
+		writer.WriteAll(rows)
+		return writer.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
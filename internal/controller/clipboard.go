@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"example.com/main/services/argocd"
+	"github.com/rivo/tview"
+)
+
+// copyText puts text on the clipboard and confirms what was copied. The
+// clipboard command runs in the background, so a hanging one cannot freeze
+// the UI.
+func (c *AppController) copyText(what string, text string) {
+	failed := func(err error) {
+		c.Model.Logger.Errorf("Could not copy %s: %v", what, err)
+		c.Notify(fmt.Sprintf("Could not copy %s: %v", what, err), true)
+	}
+
+	err := c.View.Copy(text)
+	if err != nil {
+		failed(err)
+		return
+	}
+
+	clipboard := c.View.Config.Clipboard
+	if clipboard.Command == "" {
+		c.Notify("Copied "+what, false)
+		return
+	}
+
+	c.Notify("Copying "+what, false)
+	go func() {
+		err := clipboard.Run(text)
+		c.View.App.QueueUpdateDraw(func() {
+			if err != nil {
+				failed(err)
+				return
+			}
+			c.Notify("Copied "+what, false)
+		})
+	}()
+}
+
+// CopyAppName copies the name of the selected application.
+func (c *AppController) CopyAppName() {
+	name := c.Model.SelectedAppName
+	if name == "" {
+		c.Notify("No application selected", true)
+		return
+	}
+
+	c.copyText(name, name)
+}
+
+// CopyResource copies the selected resource as kind/namespace/name, or
+// kind/name when it is not namespaced.
+func (c *AppController) CopyResource() {
+	node, ok := c.View.SelectedResource()
+	if !ok {
+		c.Notify("No resource selected", true)
+		return
+	}
+
+	ref := resourceRef(node)
+	c.copyText(ref, ref)
+}
+
+// CopyManifest fetches the live manifest of the selected resource in the
+// background and copies it as YAML.
+func (c *AppController) CopyManifest() {
+	node, ok := c.View.SelectedResource()
+	if !ok {
+		c.Notify("No resource selected", true)
+		return
+	}
+
+	app := c.Model.SelectedAppName
+	ref := resourceRef(node)
+	c.Notify("Fetching the manifest of "+ref, false)
+
+	go func() {
+		manifest, err := c.Model.ArgoCDService.ResourceManifest(context.Background(), app, node)
+		if err == nil {
+			manifest, err = argocd.ToYAML([]byte(manifest))
+		}
+
+		c.View.App.QueueUpdateDraw(func() {
+			if err != nil {
+				c.Model.Logger.Errorf("Could not fetch the manifest of %s: %v", ref, err)
+				c.Notify(fmt.Sprintf("Could not fetch the manifest of %s: %v", ref, err), true)
+				return
+			}
+
+			c.copyText(fmt.Sprintf("the manifest of %s (%d lines)", ref, strings.Count(manifest, "\n")), manifest)
+		})
+	}()
+}
+
+// CopyTable copies the rows of the focused table as tab separated values.
+func (c *AppController) CopyTable() {
+	var table *tview.Table
+	var rows, headers int
+	var name string
+
+	switch {
+	case c.View.ImagesOpen():
+		table, rows, headers, name = c.View.ImagesTable, len(c.Model.ImageReport), 1, "images"
+	case c.View.App.GetFocus() == c.View.MainTable:
+		table, rows, headers, name = c.View.MainTable, len(c.View.ShownResources()), 1, "resources"
+	default:
+		table, rows, headers, name = c.View.AppTable, len(c.Model.Applications), 0, "applications"
+	}
+
+	if rows == 0 {
+		c.Notify("Nothing to copy", true)
+		return
+	}
+
+	tsv := c.View.TableTSV(table, headers)
+	c.copyText(fmt.Sprintf("%d rows of %s as TSV", strings.Count(tsv, "\n")-headers, name), tsv)
+}
+
+func resourceRef(node argocd.ApplicationNode) string {
+	if node.Namespace == "" {
+		return node.Kind + "/" + node.Name
+	}
+	return node.Kind + "/" + node.Namespace + "/" + node.Name
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/argocd"
+	"example.com/main/services/columns"
+)
+
+// CycleSort sorts the resources by the next shown column, or the previous
+// one when dir is -1, and remembers the choice for the next session.
+func (c *AppController) CycleSort(dir int) {
+	shown := c.View.Config.Columns["resources"]
+	sort := c.View.ResourceSort
+
+	i := slices.IndexFunc(shown, func(column columns.Column) bool {
+		return column.Name == sort.Column
+	})
+	if i < 0 && dir < 0 {
+		i = 0
+	}
+	i = (i + dir + len(shown)) % len(shown)
+
+	c.setSort(columns.Sort{Column: shown[i].Name})
+}
+
+// ToggleSortOrder switches the resources between ascending and descending
+// order.
+func (c *AppController) ToggleSortOrder() {
+	sort := c.View.ResourceSort
+	sort.Descending = !sort.Descending
+	c.setSort(sort)
+}
+
+func (c *AppController) setSort(sort columns.Sort) {
+	c.View.ResourceSort = sort
+	c.RenderResources()
+
+	if c.State == nil {
+		return
+	}
+	c.State.Sort["resources"] = sort
+	if err := c.State.Save(); err != nil {
+		c.Model.Logger.Errorf("Could not save the sort order: %v", err)
+		c.Notify(fmt.Sprintf("Could not save the sort order: %v", err), true)
+	}
+}
+
+// RenderResources renders the resources of the selected application again,
+// keeping the selected resource selected.
+func (c *AppController) RenderResources() {
+	var selected *model.GlobalResult
+	if row, _ := c.View.MainTable.GetSelection(); row >= 1 && row <= len(c.FilterContent()) {
+		selected = &model.GlobalResult{App: c.Model.SelectedAppName, Node: c.FilterContent()[row-1]}
+	}
+
+	c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
+
+	if selected != nil {
+		if row := c.resourceRow(selected); row >= 0 {
+			c.View.MainTable.Select(row+1, 0)
+		}
+	}
+}
+
+// updateResourceSync takes the sync states of the resources from the
+// selected application, and shows them if they changed.
+func (c *AppController) updateResourceSync() {
+	app, _ := c.Model.Application(c.Model.SelectedAppName)
+	sync := app.ResourceSync()
+	if maps.Equal(sync, c.View.ResourceSync) {
+		return
+	}
+
+	c.View.ResourceSync = sync
+	if slices.ContainsFunc(c.View.Config.Columns["resources"], func(column columns.Column) bool {
+		return column.Name == columns.SyncStatus
+	}) && len(c.Model.SelectedAppResources) > 0 {
+		c.RenderResources()
+	}
+}
+
+// SummaryFields are the fields listed for the applications, with the sync
+// states of their resources when a view shows them.
+func SummaryFields(views map[string][]columns.Column) []string {
+	fields := slices.Clone(argocd.SummaryFields)
+	for _, shown := range views {
+		for _, column := range shown {
+			if column.Name == columns.SyncStatus {
+				return append(fields, "items.status.resources")
+			}
+		}
+	}
+	return fields
+}
@@ -1,18 +1,48 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"example.com/main/internal/model"
 	"example.com/main/internal/view"
 	"example.com/main/services/argocd"
+	"example.com/main/services/columns"
+	"example.com/main/services/config"
+	"example.com/main/services/keys"
+	"example.com/main/services/search"
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 )
 
 type AppController struct {
 	Model        *model.AppModel
 	CommandModel *model.CommandModel
+	Router       *model.KeyRouter
 	View         *view.AppView
+	State        *config.State
+	cancelLoad   context.CancelFunc
+	// the search bar: the search the pane had when it opened, the pending
+	// live search and the history being walked
+	searchBefore   search.Query
+	searchTimer    *time.Timer
+	historyContext model.Context
+	historyIndex   int
+	historyDraft   string
+	// the trees fetched for the global search or the images page, and the
+	// throttled rendering of the page
+	cancelScan        context.CancelFunc
+	scanTimer         *time.Timer
+	globalSearchTimer *time.Timer
+	// the changes of an edit under review: applying them, nil while they are
+	// invalid, and editing them again
+	applyReview func()
+	editReview  func()
 }
 
 func NewAppController(m *model.AppModel, cm *model.CommandModel, v *view.AppView) *AppController {
@@ -20,280 +50,1112 @@ func NewAppController(m *model.AppModel, cm *model.CommandModel, v *view.AppView
 	return &AppController{
 		Model:        m,
 		CommandModel: cm,
+		Router:       model.NewKeyRouter(cm),
 		View:         v,
 	}
 }
 
-func (c *AppController) AddCommands() {
+func (c *AppController) AddCommands() error {
+	errs := []error{}
+
 	// AppTable Commands
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: 'g'},
+	errs = append(errs, c.CommandModel.Add(
+		"scroll-top",
+		"g",
 		model.Global,
 		"Goes to the top of the list",
 		func(ctx model.Context) {
 			c.View.ScrollTo(0)
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: 'G'},
+	errs = append(errs, c.CommandModel.Add(
+		"scroll-bottom",
+		"G",
 		model.Global,
 		"Goes to the bottom of the list",
 		func(ctx model.Context) {
 			c.View.ScrollTo(-1)
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: 'j'},
+	errs = append(errs, c.CommandModel.Add(
+		"scroll-down",
+		"j",
 		model.Global,
 		"Scrolls down one row",
 		func(ctx model.Context) {
 			c.View.Scroll(-1)
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: 'k'},
+	errs = append(errs, c.CommandModel.Add(
+		"scroll-up",
+		"k",
 		model.Global,
 		"Scrolls up one row",
 		func(ctx model.Context) {
 			c.View.Scroll(1)
 		},
-	)
+	))
 
 	// App Commands
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: 'q'},
+	errs = append(errs, c.CommandModel.Add(
+		"focus-next",
+		"tab",
+		model.Global,
+		"Switches focus between the applications and main content",
+		func(ctx model.Context) {
+			if c.View.App.GetFocus() == c.View.MainTable {
+				c.Model.PrevFocused = c.View.AppTable
+				c.View.App.SetFocus(c.View.AppTable)
+				return
+			}
+			c.Model.PrevFocused = c.View.MainTable
+			c.View.App.SetFocus(c.View.MainTable)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"quit",
+		"q",
 		model.Global,
 		"Quits the application",
 		func(ctx model.Context) {
 			c.View.App.Stop()
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: '?'},
+	errs = append(errs, c.CommandModel.Add(
+		"toggle-help",
+		"?",
 		model.Global,
 		"Toggles the help page",
 		func(ctx model.Context) {
+			if c.View.App.GetFocus() != c.View.HelpPage {
+				c.Model.HelpContext = c.FocusedContext()
+			}
+
 			c.View.ToggleHelp()
-			c.View.UpdateHelp(c.CommandModel.Commands, "")
+			c.View.UpdateHelp(c.CommandModel, c.Model.HelpContext, search.Query{})
 
 			if c.View.App.GetFocus() == c.View.HelpPage {
 				c.Model.PrevFocused = c.View.HelpPage
 			}
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: '/'},
+	errs = append(errs, c.CommandModel.Add(
+		"toggle-search",
+		"/",
 		model.Global,
 		"Toggle Search Bar",
 		func(ctx model.Context) {
+			c.Model.CommandMode = false
 			c.View.ToggleCommandBar()
+			if c.View.SearchBarOpen() {
+				c.StartSearch()
+			}
 		},
-	)
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"command",
+		":",
+		model.Global,
+		"Runs a command, :theme picks a color theme",
+		func(ctx model.Context) {
+			c.View.ToggleCommandBar()
+			c.Model.CommandMode = c.View.SearchBarOpen()
+			if c.Model.CommandMode {
+				c.View.SearchInput.SetLabel(":")
+				c.StartHistory(model.CommandBar)
+			}
+		},
+	))
+
+	// without a search esc is left to the focused pane
+	errs = append(errs, c.CommandModel.AddWhen(
+		"clear-search",
+		"esc",
+		model.Global,
+		"Clears the search in the focused pane",
+		func() bool {
+			switch c.View.App.GetFocus() {
+			case c.View.AppTable:
+				return c.Model.AppFilter.Text != ""
+			case c.View.MainTable:
+				return c.Model.MainFilter.Text != ""
+			}
+			return false
+		},
+		func(ctx model.Context) {
+			switch c.View.App.GetFocus() {
+			case c.View.AppTable:
+				c.Model.AppFilter = search.Query{}
+				c.View.SetSearchTitle(search.Query{})
+				c.Model.AppLimit = c.View.Config.PageSize
+				c.Model.MoreApps = c.View.UpdateAppTable(c.Model.Applications, search.Query{}, c.Model.AppLimit)
+			case c.View.MainTable:
+				c.Model.MainFilter = search.Query{}
+				c.View.SetSearchTitle(search.Query{})
+				c.View.UpdateMainContent(c.Model.SelectedAppResources, search.Query{})
+			}
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"refresh",
+		"r",
+		model.Global,
+		"Reloads the applications and resources, bypassing the cache",
+		func(ctx model.Context) {
+			c.Refresh()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.AddMutating(
+		"sync",
+		"s",
+		model.AppTable,
+		"Syncs the selected application",
+		func(ctx model.Context) {
+			name := c.Model.SelectedAppName
+			c.Mutate("sync", name, func() {
+				c.Notify(fmt.Sprintf("Starting sync of %s", name), false)
+
+				go func() {
+					_, err := c.Model.ArgoCDService.SyncApplication(name)
+					c.View.App.QueueUpdateDraw(func() {
+						if err != nil {
+							c.View.ShowMessage(fmt.Sprintf("Could not sync %s: %v", name, err))
+							return
+						}
+						c.Notify(fmt.Sprintf("Sync of %s started", name), false)
+					})
+				}()
+			})
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"next-match",
+		"n",
+		model.Global,
+		"Goes to the next row matching the search",
+		func(ctx model.Context) {
+			c.View.NextMatch(1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"previous-match",
+		"N",
+		model.Global,
+		"Goes to the previous row matching the search",
+		func(ctx model.Context) {
+			c.View.NextMatch(-1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"toggle-highlight",
+		"ctrl+t",
+		model.App,
+		"Switches between filtering rows and highlighting the matches of a search",
+		func(ctx model.Context) {
+			c.ToggleHighlight()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"toggle-detail",
+		"d",
+		model.MainPage,
+		"Shows every field, the owners and the labels of the selected resource",
+		func(ctx model.Context) {
+			c.View.ToggleDetail()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"sort-next",
+		"o",
+		model.MainPage,
+		"Sorts the resources by the next column",
+		func(ctx model.Context) {
+			c.CycleSort(1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"sort-previous",
+		"alt+o",
+		model.MainPage,
+		"Sorts the resources by the previous column",
+		func(ctx model.Context) {
+			c.CycleSort(-1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"sort-order",
+		"O",
+		model.MainPage,
+		"Switches the resources between ascending and descending order",
+		func(ctx model.Context) {
+			c.ToggleSortOrder()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.AddMutating(
+		"edit",
+		"e",
+		model.AppTable,
+		"Edits the spec of the selected application in $EDITOR and applies the changes",
+		func(ctx model.Context) {
+			c.EditApplication()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.AddMutating(
+		"edit-manifest",
+		"e",
+		model.MainPage,
+		"Edits the live manifest of the selected resource in $EDITOR and applies the changes",
+		func(ctx model.Context) {
+			c.EditResource()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"copy-name",
+		"c",
+		model.AppTable,
+		"Copies the name of the selected application",
+		func(ctx model.Context) {
+			c.CopyAppName()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"copy-resource",
+		"c",
+		model.MainPage,
+		"Copies the selected resource as kind/namespace/name",
+		func(ctx model.Context) {
+			c.CopyResource()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"copy-manifest",
+		"C",
+		model.MainPage,
+		"Copies the live manifest of the selected resource as YAML",
+		func(ctx model.Context) {
+			c.CopyManifest()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"copy-table",
+		"y",
+		model.Global,
+		"Copies the rows of the focused table as tab separated values",
+		func(ctx model.Context) {
+			c.CopyTable()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"global-search",
+		"F",
+		model.Global,
+		"Searches the resources of every application",
+		func(ctx model.Context) {
+			c.OpenGlobalSearch()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"images",
+		"I",
+		model.Global,
+		"Reports the images of every application and the ones running in more than one version",
+		func(ctx model.Context) {
+			c.OpenImages()
+		},
+	))
 
 	// Help Page Commands
-	c.CommandModel.Add(
-		model.KeyStroke{Key: tcell.KeyEsc},
+	errs = append(errs, c.CommandModel.Add(
+		"close",
+		"esc",
 		model.Help,
-		"Exit Search Page",
+		"Clears the search or exits the help page",
 		func(ctx model.Context) {
+			if c.Model.HelpFilter.Text != "" {
+				c.Model.HelpFilter = search.Query{}
+				c.View.SetSearchTitle(search.Query{})
+				c.View.UpdateHelp(c.CommandModel, c.Model.HelpContext, search.Query{})
+				return
+			}
+
 			c.View.ToggleHelp()
 		},
-	)
+	))
 
 	// Command Bar Commands
-	c.CommandModel.Add(
-		model.KeyStroke{Rune: '/'},
+	errs = append(errs, c.CommandModel.Add(
+		"toggle-search",
+		"/",
 		model.CommandBar,
 		"Toggle Search Bar",
 		func(ctx model.Context) {
-			c.View.ToggleCommandBar()
+			c.closeCommandBar()
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Key: tcell.KeyEsc},
+	errs = append(errs, c.CommandModel.Add(
+		"close",
+		"esc",
 		model.CommandBar,
 		"Toggle Search Bar",
 		func(ctx model.Context) {
-			c.View.ToggleCommandBar()
+			c.closeCommandBar()
 		},
-	)
+	))
 
-	c.CommandModel.Add(
-		model.KeyStroke{Key: tcell.KeyEnter},
+	errs = append(errs, c.CommandModel.Add(
+		"history-previous",
+		"up",
+		model.CommandBar,
+		"Shows the previous search of the pane, or the previous command",
+		func(ctx model.Context) {
+			c.WalkHistory(-1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"history-next",
+		"down",
+		model.CommandBar,
+		"Shows the next search of the pane, or the next command",
+		func(ctx model.Context) {
+			c.WalkHistory(1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"search",
+		"enter",
 		model.CommandBar,
 		"Search for substrings in the currently focused pane",
 		func(ctx model.Context) {
 			searchText := c.View.SearchInput.GetText()
+
+			if c.Model.CommandMode {
+				c.View.ToggleCommandBar()
+				c.View.App.SetFocus(c.Model.PrevFocused)
+				c.Model.CommandMode = false
+				c.Model.AddSearchHistory(model.CommandBar, searchText)
+				c.RunCommand(searchText)
+				return
+			}
+
+			// an invalid query keeps the search bar open to be fixed
+			query, err := search.Parse(searchText, c.SearchFields()...)
+			if err != nil {
+				c.View.ShowSearchError(searchText, err)
+				return
+			}
+
+			c.stopSearch()
+			c.Model.AddSearchHistory(c.searchContext(), searchText)
 			c.View.ToggleCommandBar()
 			c.View.App.SetFocus(c.Model.PrevFocused)
-			c.View.SetSearchTitle(searchText)
+			c.ApplySearch(query)
+		},
+	))
 
-			switch c.View.App.GetFocus() {
-			case c.View.AppTable:
-				c.Model.AppFilter = searchText
-				c.View.UpdateAppTable(c.Model.Applications, c.Model.AppFilter)
-			case c.View.MainTable:
-				c.Model.MainFilter = searchText
-				c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
-			case c.View.HelpPage:
-				c.Model.HelpFilter = searchText
-				c.View.UpdateHelp(c.CommandModel.Commands, c.Model.HelpFilter)
-			}
+	// Global Search Commands
+	errs = append(errs, c.CommandModel.Add(
+		"close",
+		"esc",
+		model.Search,
+		"Closes the global search",
+		func(ctx model.Context) {
+			c.CloseGlobalSearch()
 		},
-	)
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"jump",
+		"enter",
+		model.Search,
+		"Shows the selected resource in its application",
+		func(ctx model.Context) {
+			c.JumpToSelectedResource()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"result-up",
+		"up",
+		model.Search,
+		"Selects the previous result",
+		func(ctx model.Context) {
+			c.View.MoveGlobalSelection(-1)
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"result-down",
+		"down",
+		model.Search,
+		"Selects the next result",
+		func(ctx model.Context) {
+			c.View.MoveGlobalSelection(1)
+		},
+	))
+
+	// Images Commands
+	errs = append(errs, c.CommandModel.Add(
+		"close",
+		"esc",
+		model.Images,
+		"Closes the images page",
+		func(ctx model.Context) {
+			c.CloseImages()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"export-csv",
+		"e",
+		model.Images,
+		"Exports the images report as CSV to the working directory",
+		func(ctx model.Context) {
+			c.ExportImages("csv")
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"export-json",
+		"E",
+		model.Images,
+		"Exports the images report as JSON to the working directory",
+		func(ctx model.Context) {
+			c.ExportImages("json")
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"copy-table",
+		"y",
+		model.Images,
+		"Copies the images report as tab separated values",
+		func(ctx model.Context) {
+			c.CopyTable()
+		},
+	))
+
+	// Review Commands
+	errs = append(errs, c.CommandModel.Add(
+		"apply",
+		"enter",
+		model.Review,
+		"Applies the changes of the edit",
+		func(ctx model.Context) {
+			c.ApplyReview()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"edit-again",
+		"e",
+		model.Review,
+		"Opens the changes in the editor again",
+		func(ctx model.Context) {
+			c.EditReview()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Add(
+		"discard",
+		"esc",
+		model.Review,
+		"Discards the changes of the edit",
+		func(ctx model.Context) {
+			c.DiscardReview()
+		},
+	))
+
+	errs = append(errs, c.CommandModel.Validate())
+
+	return errors.Join(errs...)
 }
 
-func (c *AppController) SetupEventHandlers() {
-	c.AddCommands()
+// closeCommandBar closes the command bar, cancelling a search that was not
+// confirmed.
+func (c *AppController) closeCommandBar() {
+	if c.Model.CommandMode {
+		c.Model.CommandMode = false
+		c.View.ToggleCommandBar()
+		return
+	}
 
-	c.View.AppTable.SetSelectionChangedFunc(func(row int, col int) {
-		name := c.View.AppTable.GetCell(row, col)
-		c.Model.LoadResources(name.Text)
-		c.View.UpdateMainContent(c.Model.SelectedAppResources, "")
-	})
+	c.CloseSearch()
+}
 
-	// apptable commands
-	c.View.AppTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyRune {
-			if cmd, ok := c.CommandModel.Commands[model.AppTable][model.KeyStroke{Rune: event.Rune()}]; ok {
-				cmd.Handler()
-				return nil
-			}
-		}
+// SetupEventHandlers binds the commands and input handlers. Bindings that
+// could not be applied are returned, the rest of the commands still work.
+func (c *AppController) SetupEventHandlers() error {
+	err := c.AddCommands()
 
-		return event
-	})
+	c.View.AppTable.SetSelectionChangedFunc(c.AppSelected)
 
-	// help page cmds
-	c.View.HelpPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyRune {
-			// runes
-			if cmd, ok := c.CommandModel.Commands[model.Help][model.KeyStroke{Rune: event.Rune()}]; ok {
-				cmd.Handler()
-				return nil
-			}
-		}
+	c.View.App.SetInputCapture(c.RouteKey)
 
-		// keys
-		if cmd, ok := c.CommandModel.Commands[model.Help][model.KeyStroke{Key: event.Key()}]; ok {
-			cmd.Handler()
-			return nil
+	return err
+}
+
+func (c *AppController) AppSelected(row int, col int) {
+	if len(c.Model.Applications) == 0 {
+		return
+	}
+
+	c.LoadResources(c.View.AppName(row))
+
+	// reaching the last rendered application renders the next page
+	if c.Model.MoreApps && row == c.View.AppTable.GetRowCount()-1 {
+		c.Model.AppLimit += c.View.Config.PageSize
+		c.RenderApps()
+	}
+}
+
+// BindingProblems converts the errors returned by AddCommands into problems
+// located at the offending entry of the config file.
+func BindingProblems(cfg *config.Config, err error) []config.Problem {
+	problems := []config.Problem{}
+	if err == nil {
+		return problems
+	}
+
+	// AddCommands joins errors, some of which are joined themselves
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			problems = append(problems, BindingProblems(cfg, err)...)
 		}
+		return problems
+	}
 
-		return event
+	var bindingErr *model.BindingError
+	if !errors.As(err, &bindingErr) {
+		return append(problems, config.Problem{Message: err.Error()})
+	}
+
+	path := []string{"keys", strings.ToLower(string(bindingErr.Context))}
+	if bindingErr.ID != "" {
+		path = append(path, bindingErr.ID)
+	}
+
+	return append(problems, cfg.Problem(bindingErr.Message, path...))
+}
+
+// CheckBindings applies the key bindings of cfg without starting the UI, for
+// the config check command.
+func CheckBindings(cfg *config.Config) []config.Problem {
+	c := &AppController{
+		Model:        &model.AppModel{},
+		CommandModel: model.NewCommandModel(cfg.Keys),
+		View:         &view.AppView{Config: cfg},
+	}
+
+	return BindingProblems(cfg, c.AddCommands())
+}
+
+func (c *AppController) ShowProblems(title string, problems []config.Problem) {
+	lines := []string{}
+	for _, problem := range problems {
+		lines = append(lines, problem.String())
+	}
+
+	c.View.ShowProblems(title, lines)
+}
+
+// WatchConfig reloads the config file whenever it changes. The returned
+// function stops watching.
+func (c *AppController) WatchConfig() func() {
+	path := c.View.Config.Path
+
+	return config.Watch(path, config.DefaultWatchInterval, func() {
+		cfg, err := config.Load(path)
+		c.View.App.QueueUpdateDraw(func() {
+			c.ReloadConfig(cfg, err)
+		})
 	})
+}
 
-	// main page cmds
-	c.View.MainPage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyRune {
-			if cmd, ok := c.CommandModel.Commands[model.MainPage][model.KeyStroke{Rune: event.Rune()}]; ok {
-				cmd.Handler()
-				return nil
-			}
+// ReloadConfig applies a reloaded config file, its colors and key bindings.
+// A file with problems is reported and the current config is kept, so a
+// half-finished edit does not break the running UI.
+func (c *AppController) ReloadConfig(cfg *config.Config, err error) {
+	if err != nil {
+		c.View.ShowMessage(fmt.Sprintf("Could not reload %s: %v\nKeeping the current config.", c.View.Config.Path, err))
+		return
+	}
+
+	problems := append(slices.Clone(cfg.Problems), CheckBindings(cfg)...)
+	if len(problems) > 0 {
+		config.SortProblems(problems)
+		c.ShowProblems(fmt.Sprintf(" Problems in %s, kept the current config ", cfg.Path), problems)
+		return
+	}
+
+	commands := model.NewCommandModel(cfg.Keys)
+	commands.Readonly = c.CommandModel.Readonly
+	commands.Context = c.CommandModel.Context
+	c.CommandModel = commands
+	c.Router = model.NewKeyRouter(commands)
+
+	// already checked above
+	_ = c.AddCommands()
+
+	c.Model.ArgoCDService.SetCacheLimits(cfg.CacheTTL, cfg.CacheSize)
+	if cfg.PageSize == 0 || c.Model.AppLimit < cfg.PageSize {
+		c.Model.AppLimit = cfg.PageSize
+	}
+	c.Restyle(cfg)
+	c.Notify(fmt.Sprintf("Reloaded %s", cfg.Path), false)
+	c.Model.Logger.Infof("Reloaded config %s", cfg.Path)
+}
+
+// Restyle switches to the colors of cfg and renders the UI again.
+func (c *AppController) Restyle(cfg *config.Config) {
+	c.View.ApplyConfig(cfg)
+	c.SetBanner()
+
+	c.RenderApps()
+	c.UpdateStatus()
+
+	row, _ := c.View.MainTable.GetSelection()
+	c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
+	c.View.MainTable.Select(row, 0)
+
+	if page, _ := c.View.Pages.GetFrontPage(); page == "help page" {
+		c.View.UpdateHelp(c.CommandModel, c.Model.HelpContext, c.Model.HelpFilter)
+	}
+}
+
+// RenderApps renders the applications again, for example after a change
+// from the watch stream. The selection stays on the same application without
+// loading its resources again.
+func (c *AppController) RenderApps() {
+	c.View.AppTable.SetSelectionChangedFunc(nil)
+	row, _ := c.View.AppTable.GetSelection()
+	c.Model.MoreApps = c.View.UpdateAppTable(c.Model.Applications, c.Model.AppFilter, c.Model.AppLimit)
+	found := c.RevealApp(c.Model.SelectedAppName)
+	c.View.AppTable.SetSelectionChangedFunc(c.AppSelected)
+
+	if found || len(c.Model.Applications) == 0 || c.View.AppTable.GetRowCount() == 0 {
+		return
+	}
+
+	// nothing was selected yet, or the selected application is gone and its
+	// neighbour takes over
+	if c.Model.SelectedAppName == "" {
+		row = 0
+	}
+	c.View.AppTable.Select(min(row, c.View.AppTable.GetRowCount()-1), 0)
+}
+
+// RevealApp selects the application called name, rendering more pages until
+// it shows up. It returns false when name is not listed at all.
+func (c *AppController) RevealApp(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for !c.View.SelectApp(name) {
+		if !c.Model.MoreApps {
+			return false
 		}
+		c.Model.AppLimit += c.View.Config.PageSize
+		c.Model.MoreApps = c.View.UpdateAppTable(c.Model.Applications, c.Model.AppFilter, c.Model.AppLimit)
+	}
 
-		return event
+	return true
+}
+
+// Notify shows a transient message in the status bar.
+func (c *AppController) Notify(message string, isError bool) {
+	c.Model.Status.Message = message
+	c.Model.Status.MessageIsError = isError
+	c.Model.Status.MessageExpires = time.Now().Add(statusMessageTimeout)
+	c.UpdateStatus()
+}
+
+const statusMessageTimeout = 5 * time.Second
+
+func (c *AppController) UpdateStatus() {
+	now := time.Now()
+	if c.Model.Status.Message != "" && now.After(c.Model.Status.MessageExpires) {
+		c.Model.Status.Message = ""
+	}
+
+	c.View.UpdateStatus(c.Model.Status, c.Model.Applications, now)
+}
+
+// LoadServerInfo fetches the logged in user and the server version for the
+// status bar. It runs in the background, failures are only reported.
+func (c *AppController) LoadServerInfo() {
+	user, err := c.Model.ArgoCDService.GetUserInfo()
+	if err != nil {
+		c.Model.Logger.Warnf("Could not get user info: %v", err)
+	}
+
+	version, verr := c.Model.ArgoCDService.GetVersion()
+	if verr != nil {
+		c.Model.Logger.Warnf("Could not get server version: %v", verr)
+	}
+
+	c.View.App.QueueUpdateDraw(func() {
+		if user != nil {
+			c.Model.Status.User = user.Username
+		}
+		if version != nil {
+			c.Model.Status.Version = version.Version
+		}
+		if err != nil || verr != nil {
+			c.Notify("Could not get the user or server version, see the log", true)
+			return
+		}
+		c.UpdateStatus()
 	})
+}
+
+const (
+	watchRetryInterval = 5 * time.Second
+	// watchFlushInterval batches the changes from the watch stream, so a burst
+	// of events redraws the applications once.
+	watchFlushInterval = 250 * time.Millisecond
+)
 
-	// global cmds
-	c.View.App.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if c.View.CommandBar.HasFocus() {
-			return event
+// WatchApplications lists the applications and keeps them up to date from the
+// watch stream, listing again and reconnecting when it breaks, until ctx is
+// cancelled.
+func (c *AppController) WatchApplications(ctx context.Context) {
+	setState := func(state model.WatchState) {
+		c.View.App.QueueUpdateDraw(func() {
+			c.Model.Status.Watch = state
+			c.UpdateStatus()
+		})
+	}
+
+	var mu sync.Mutex
+	var pending []argocd.ApplicationWatchEvent
+	// the lock is held while queueing, so batches are applied in order
+	flush := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events := pending
+		pending = nil
+		if len(events) == 0 {
+			return
 		}
 
-		if event.Key() == tcell.KeyRune {
-			// global commands
-			if cmd, ok := c.CommandModel.Commands[model.Global][model.KeyStroke{Rune: event.Rune()}]; ok {
-				cmd.Handler()
-				return nil
+		c.View.App.QueueUpdateDraw(func() {
+			c.Model.ApplyWatchEvents(events)
+			c.RenderApps()
+			c.updateResourceSync()
+			c.UpdateStatus()
+		})
+	}
+
+	go func() {
+		ticker := time.NewTicker(watchFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				flush()
 			}
 		}
+	}()
 
-		if event.Key() == tcell.KeyTab {
-			if c.View.App.GetFocus() == c.View.MainTable {
-				c.Model.PrevFocused = c.View.AppTable
-				c.View.App.SetFocus(c.View.AppTable)
-				return nil
-			}
-			c.Model.PrevFocused = c.View.MainTable
-			c.View.App.SetFocus(c.View.MainTable)
-			return nil
+	version := c.LoadApplications(ctx, false)
+	for {
+		err := c.Model.ArgoCDService.WatchApplications(
+			ctx,
+			version,
+			func() {
+				setState(model.WatchLive)
+			},
+			func(event argocd.ApplicationWatchEvent) {
+				mu.Lock()
+				pending = append(pending, event)
+				mu.Unlock()
+			},
+		)
+
+		if ctx.Err() != nil {
+			return
 		}
 
-		if event.Key() == tcell.KeyEsc {
-			switch c.View.App.GetFocus() {
-			case c.View.AppTable:
-				if c.Model.AppFilter != "" {
-					c.Model.AppFilter = ""
-					c.View.SetSearchTitle("")
-					c.View.UpdateAppTable(c.Model.Applications, "")
-					return nil
-				}
-			case c.View.MainTable:
-				if c.Model.MainFilter != "" {
-					c.Model.MainFilter = ""
-					c.View.SetSearchTitle("")
-					c.View.UpdateMainContent(c.Model.SelectedAppResources, "")
-					return nil
-				}
-			case c.View.HelpPage:
-				if c.Model.HelpFilter != "" {
-					c.Model.HelpFilter = ""
-					c.View.SetSearchTitle("")
-					c.View.UpdateHelp(c.CommandModel.Commands, "")
-					return nil
-				}
-			}
+		// queued before the list below, so it cannot undo it
+		flush()
 
-			return event
+		c.Model.Logger.Warnf("Watch stream stopped: %v", err)
+		c.View.App.QueueUpdateDraw(func() {
+			c.Model.Status.Watch = model.WatchReconnecting
+			c.Notify(fmt.Sprintf("Watch stream stopped, retrying in %s", watchRetryInterval), true)
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryInterval):
 		}
 
-		return event
-	})
+		// deletions missed while disconnected do not show up in the stream,
+		// and a cached list is older than what the stream already delivered
+		version = c.LoadApplications(ctx, true)
+	}
+}
 
-	// command bar cmds
-	c.View.CommandBar.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		// handle runes
-		if event.Key() == tcell.KeyRune {
-			if cmd, ok := c.CommandModel.Commands[model.CommandBar][model.KeyStroke{Rune: event.Rune()}]; ok {
-				cmd.Handler()
-				return nil
-			}
+// tickStatus redraws the status bar every second so the time since the last
+// update stays current and expired messages disappear.
+func (c *AppController) tickStatus(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.View.App.QueueUpdateDraw(c.UpdateStatus)
 		}
+	}
+}
+
+// RunCommand runs a line entered after ':'.
+func (c *AppController) RunCommand(line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
 
-		// handle tcell.Key
-		if cmd, ok := c.CommandModel.Commands[model.CommandBar][model.KeyStroke{Key: event.Key()}]; ok {
-			cmd.Handler()
-			return event
+	switch fields[0] {
+	case "theme":
+		if len(fields) > 1 {
+			c.SetTheme(fields[1])
+			return
 		}
+		c.PickTheme()
+	default:
+		c.View.ShowMessage(fmt.Sprintf("Unknown command %q", fields[0]))
+	}
+}
 
-		// if no event found, bubble event
-		return event
-	})
+func (c *AppController) SetTheme(name string) {
+	cfg, err := c.View.Config.WithTheme(name)
+	if err != nil {
+		c.View.ShowMessage(err.Error())
+		return
+	}
+
+	c.Restyle(cfg)
 }
 
-func (c *AppController) FilterContent() []argocd.ApplicationNode {
-	var filteredResources []argocd.ApplicationNode
+// PickTheme previews each theme while it is highlighted. Cancelling restores
+// the colors from before. The choice lasts until the config is reloaded, set
+// theme in the config file to keep it.
+func (c *AppController) PickTheme() {
+	original := *c.View.Config
 
-	for _, app := range c.Model.SelectedAppResources {
-		if strings.Contains(app.Name, c.Model.MainFilter) {
-			filteredResources = append(filteredResources, app)
-		}
+	current := original.Theme
+	if current == "" {
+		current = config.DefaultTheme
+	}
+
+	c.View.Pick(
+		" Theme ",
+		config.ThemeNames(config.ThemesDir(original.Path)),
+		current,
+		func(name string) error {
+			cfg, err := original.WithTheme(name)
+			if err != nil {
+				c.Restyle(&original)
+				return err
+			}
+
+			c.Restyle(cfg)
+			return nil
+		},
+		func(name string, ok bool) {
+			if !ok {
+				c.Restyle(&original)
+				return
+			}
+
+			c.SetTheme(name)
+		},
+	)
+}
+
+// Mutate runs a command that changes the cluster. It is refused in read-only
+// mode, and on protected servers the application name has to be typed first.
+func (c *AppController) Mutate(action string, appName string, run func()) {
+	if c.refuseReadonly(action, appName) {
+		return
+	}
+
+	if c.Model.Protected {
+		c.View.Confirm(
+			fmt.Sprintf("%s is protected. Type [::b]%s[::-] to %s it.", c.Model.ContextName, tview.Escape(appName), action),
+			appName,
+			run,
+		)
+		return
+	}
+
+	run()
+}
+
+// refuseReadonly tells that action is refused in read-only mode, and whether
+// it was.
+func (c *AppController) refuseReadonly(action string, appName string) bool {
+	if c.CommandModel.Readonly {
+		c.View.ShowMessage(fmt.Sprintf("Refusing to %s %s in read-only mode", action, appName))
+	}
+	return c.CommandModel.Readonly
+}
+
+func (c *AppController) SetBanner() {
+	switch {
+	case c.CommandModel.Readonly:
+		c.View.SetBanner(
+			fmt.Sprintf("READ-ONLY %s - changes to the cluster are disabled", c.Model.ContextName),
+			c.View.Config.Progressing,
+		)
+	case c.Model.Protected:
+		c.View.SetBanner(
+			fmt.Sprintf("PROTECTED %s - changes must be confirmed with the application name", c.Model.ContextName),
+			c.View.Config.Degraded,
+		)
+	}
+}
+
+// FocusedContext maps the focused primitive to the context its key bindings
+// are resolved in.
+func (c *AppController) FocusedContext() model.Context {
+	switch c.View.App.GetFocus() {
+	case c.View.AppTable:
+		return model.AppTable
+	case c.View.MainTable:
+		return model.MainPage
+	case c.View.HelpPage:
+		return model.Help
+	}
+
+	if c.View.CommandBar.HasFocus() {
+		return model.CommandBar
+	}
+
+	if c.View.GlobalSearchOpen() {
+		return model.Search
+	}
+
+	if c.View.ImagesOpen() {
+		return model.Images
+	}
+
+	if c.View.DiffOpen() {
+		return model.Review
+	}
+
+	if c.View.HasDialog() {
+		return model.Dialog
+	}
+
+	return model.Global
+}
+
+func (c *AppController) RouteKey(event *tcell.EventKey) *tcell.EventKey {
+	stack := model.Stack(c.FocusedContext())
+	cmd, consumed := c.Router.Dispatch(stack, keys.FromEvent(event), event.When())
+
+	if cmd != nil {
+		cmd.Handler()
+	}
+
+	if !consumed {
+		return event
 	}
 
-	return filteredResources
+	if cmd == nil {
+		// wait for the rest of the sequence, then fire the shorter binding
+		time.AfterFunc(c.Router.Timeout, func() {
+			c.View.App.QueueUpdateDraw(func() {
+				if cmd := c.Router.Expire(stack, time.Now()); cmd != nil {
+					cmd.Handler()
+				}
+			})
+		})
+	}
+
+	return nil
+}
+
+// FilterContent returns the resources shown in the main table, in the order
+// they are shown.
+func (c *AppController) FilterContent() []argocd.ApplicationNode {
+	return c.View.ShownResources()
 }
 
 func (c *AppController) Start() error {
-	c.SetupEventHandlers()
-	c.Model.LoadApplications()
-	c.View.UpdateAppTable(c.Model.Applications, "")
+	problems := append(
+		slices.Clone(c.View.Config.Problems),
+		BindingProblems(c.View.Config, c.SetupEventHandlers())...,
+	)
+
+	c.SetBanner()
+	c.Model.AppLimit = c.View.Config.PageSize
+	c.View.ResourceSort = columns.Sort{Column: columns.Name}
+	if c.State != nil {
+		if sort, ok := c.State.Sort["resources"]; ok {
+			c.View.ResourceSort = sort
+		}
+	}
+	c.View.ShowPlaceholder(c.View.AppTable, "Loading applications…", c.View.Config.Progressing)
 	c.View.App.SetRoot(c.View.Pages, true)
+
+	c.Model.Status.Server = c.Model.ArgoCDService.ServerURL
+	c.Model.Status.Context = c.Model.ContextName
+	c.Model.Status.Watch = model.WatchConnecting
+	c.UpdateStatus()
+
+	if len(problems) > 0 {
+		config.SortProblems(problems)
+		c.ShowProblems(fmt.Sprintf(" Problems in %s ", c.View.Config.Path), problems)
+	}
+
+	stop := c.WatchConfig()
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go c.LoadServerInfo()
+	go c.WatchApplications(ctx)
+	go c.tickStatus(ctx)
+
 	return c.View.App.Run()
 }
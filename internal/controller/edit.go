@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/argocd"
+	"example.com/main/services/diff"
+	"github.com/rivo/tview"
+)
+
+// edit is a change to the spec of an application, or to the live manifest
+// of one of its resources when Node is set. Original is the YAML as it was
+// fetched.
+type edit struct {
+	App      string
+	Node     *argocd.ApplicationNode
+	Subject  string
+	Original string
+}
+
+// EditApplication opens the spec of the selected application in the editor.
+func (c *AppController) EditApplication() {
+	name := c.Model.SelectedAppName
+	if name == "" {
+		c.Notify("No application selected", true)
+		return
+	}
+	if c.refuseReadonly("edit", name) {
+		return
+	}
+
+	c.fetchEdit(edit{App: name, Subject: "the spec of " + name}, func(ctx context.Context) ([]byte, error) {
+		return c.Model.ArgoCDService.ApplicationSpec(ctx, name)
+	})
+}
+
+// EditResource opens the live manifest of the selected resource in the
+// editor.
+func (c *AppController) EditResource() {
+	node, ok := c.View.SelectedResource()
+	if !ok {
+		c.Notify("No resource selected", true)
+		return
+	}
+	app := c.Model.SelectedAppName
+	if c.refuseReadonly("edit", resourceRef(node)) {
+		return
+	}
+
+	c.fetchEdit(edit{App: app, Node: &node, Subject: resourceRef(node)}, func(ctx context.Context) ([]byte, error) {
+		manifest, err := c.Model.ArgoCDService.ResourceManifest(ctx, app, node)
+		return []byte(manifest), err
+	})
+}
+
+// fetchEdit fetches the document to edit in the background and opens it in
+// the editor as YAML.
+func (c *AppController) fetchEdit(e edit, fetch func(ctx context.Context) ([]byte, error)) {
+	c.Notify("Fetching "+e.Subject, false)
+
+	go func() {
+		document, err := fetch(context.Background())
+		var text string
+		if err == nil {
+			text, err = argocd.ToYAML(document)
+		}
+
+		c.View.App.QueueUpdateDraw(func() {
+			if err != nil {
+				c.Model.Logger.Errorf("Could not fetch %s: %v", e.Subject, err)
+				c.Notify(fmt.Sprintf("Could not fetch %s: %v", e.Subject, err), true)
+				return
+			}
+
+			e.Original = text
+			c.openEditor(e, text)
+		})
+	}()
+}
+
+// openEditor edits text while the terminal UI is suspended, then shows the
+// changes for review. Emptying the file cancels the edit.
+func (c *AppController) openEditor(e edit, text string) {
+	edited, err := c.runEditor(e, text)
+	if err != nil {
+		c.Model.Logger.Errorf("Could not edit %s: %v", e.Subject, err)
+		c.View.ShowMessage(fmt.Sprintf("Could not edit %s: %v", e.Subject, err))
+		return
+	}
+
+	if strings.TrimSpace(edited) == "" {
+		c.Notify("Cancelled the edit of "+e.Subject, false)
+		return
+	}
+
+	lines := diff.Lines(e.Original, edited)
+	if !diff.Changed(lines) {
+		c.Notify("No changes to "+e.Subject, false)
+		return
+	}
+
+	c.reviewEdit(e, edited, lines, "")
+}
+
+// runEditor writes text to a temporary file, runs the editor on it and
+// returns what was saved.
+func (c *AppController) runEditor(e edit, text string) (string, error) {
+	file, err := os.CreateTemp("", "argocd-tui-"+e.App+"-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	command := c.View.Config.EditorCommand()
+	cmd := exec.Command(command[0], append(command[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	var runErr error
+	if !c.View.App.Suspend(func() { runErr = cmd.Run() }) {
+		return "", errors.New("could not suspend the terminal")
+	}
+	if runErr != nil {
+		return "", fmt.Errorf("error running %s: %w", command[0], runErr)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	return string(edited), err
+}
+
+// reviewEdit shows the changes with problem, the first problem found
+// validating them, and applies them once confirmed.
+func (c *AppController) reviewEdit(e edit, edited string, lines []diff.Line, problem string) {
+	document, err := argocd.FromYAML(edited)
+	if err == nil {
+		if e.Node == nil {
+			err = argocd.ValidateSpec(document)
+		} else {
+			err = argocd.ValidateManifest(document, *e.Node)
+		}
+	}
+
+	c.applyReview = nil
+	if err != nil {
+		problem = "Invalid " + e.Subject + ": " + err.Error()
+	} else {
+		c.applyReview = func() {
+			c.Mutate("edit", e.App, func() {
+				c.applyEdit(e, edited, document)
+			})
+		}
+	}
+	c.editReview = func() {
+		c.openEditor(e, edited)
+	}
+
+	hints := []string{}
+	for _, id := range []string{"apply", "edit-again", "discard"} {
+		cmd := c.CommandModel.Get(model.Review, id)
+		if cmd == nil || (id == "apply" && c.applyReview == nil) {
+			continue
+		}
+		hints = append(hints, fmt.Sprintf("[::b]%s[::-] %s", tview.Escape(cmd.Keys.Display()), strings.ReplaceAll(id, "-", " ")))
+	}
+
+	c.View.ShowDiff(" Changes to "+e.Subject+" ", lines, problem, strings.Join(hints, "   "))
+}
+
+// ApplyReview applies the changes under review, unless they are invalid.
+func (c *AppController) ApplyReview() {
+	apply := c.applyReview
+	if apply == nil {
+		return
+	}
+
+	c.closeReview()
+	apply()
+}
+
+// EditReview opens the changes under review in the editor again.
+func (c *AppController) EditReview() {
+	edit := c.editReview
+	c.closeReview()
+	if edit != nil {
+		edit()
+	}
+}
+
+// DiscardReview closes the changes under review without applying them.
+func (c *AppController) DiscardReview() {
+	c.closeReview()
+}
+
+func (c *AppController) closeReview() {
+	c.applyReview = nil
+	c.editReview = nil
+	c.View.HideDiff()
+}
+
+// applyEdit replaces the spec of the application, or patches the resource
+// with the fields that changed. A failure shows the changes again to retry
+// or edit them.
+func (c *AppController) applyEdit(e edit, edited string, document map[string]any) {
+	c.Notify("Applying the changes to "+e.Subject, false)
+
+	go func() {
+		var err error
+		if e.Node == nil {
+			err = c.Model.ArgoCDService.UpdateApplicationSpec(context.Background(), e.App, document)
+		} else {
+			var original map[string]any
+			original, err = argocd.FromYAML(e.Original)
+			if err == nil {
+				patch := argocd.MergePatch(original, document)
+				err = c.Model.ArgoCDService.PatchResource(context.Background(), e.App, *e.Node, patch)
+			}
+		}
+
+		c.View.App.QueueUpdateDraw(func() {
+			if err != nil {
+				c.Model.Logger.Errorf("Could not apply the changes to %s: %v", e.Subject, err)
+				c.reviewEdit(e, edited, diff.Lines(e.Original, edited), fmt.Sprintf("Could not apply the changes: %v", err))
+				return
+			}
+
+			c.Notify("Applied the changes to "+e.Subject, false)
+			c.Refresh()
+		})
+	}()
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/search"
+)
+
+// OpenGlobalSearch opens the search across the resources of every
+// application. Cached trees are searched right away, the missing and stale
+// ones are fetched in the background and searched as they arrive.
+func (c *AppController) OpenGlobalSearch() {
+	c.CloseGlobalSearch()
+
+	scan, requests := c.Model.NewTreeScan()
+	c.Model.Global = model.GlobalSearch{TreeScan: scan}
+
+	ctx := c.startScan(&c.Model.Global.TreeScan, requests, c.renderGlobal)
+
+	c.View.ShowGlobalSearch()
+	c.View.GlobalInput.SetChangedFunc(func(text string) {
+		c.globalChanged(ctx, text)
+	})
+	c.renderGlobal()
+}
+
+func (c *AppController) globalChanged(ctx context.Context, text string) {
+	query, err := search.Parse(text, search.GlobalFields...)
+	if err != nil {
+		c.View.ShowGlobalError(text, err)
+		return
+	}
+
+	c.stopGlobalSearch()
+	c.globalSearchTimer = time.AfterFunc(searchDebounce, func() {
+		c.View.App.QueueUpdateDraw(func() {
+			// the search was closed or changed meanwhile
+			if ctx.Err() != nil || c.View.GlobalInput.GetText() != text {
+				return
+			}
+			c.Model.Global.Filter = query
+			c.renderGlobal()
+		})
+	})
+}
+
+func (c *AppController) stopGlobalSearch() {
+	if c.globalSearchTimer != nil {
+		c.globalSearchTimer.Stop()
+	}
+}
+
+// renderGlobal searches the trees fetched so far and shows the results,
+// keeping the selected one.
+func (c *AppController) renderGlobal() {
+	global := &c.Model.Global
+
+	selected := c.View.GlobalSelection()
+	var before *model.GlobalResult
+	if selected >= 0 && selected < len(global.Results) {
+		before = &global.Results[selected]
+	}
+
+	global.Find(c.Model.Applications)
+
+	row := 0
+	if before != nil {
+		for i, result := range global.Results {
+			if before.Is(result.App, result.Node) {
+				row = i
+				break
+			}
+		}
+	}
+
+	c.View.UpdateGlobalResults(global.Results, global.Filter)
+	c.View.GlobalTable.Select(row+1, 0)
+
+	found := -1
+	if !global.Filter.Empty() {
+		found = len(global.Results)
+	}
+	c.View.SetGlobalStatus(global.Searched(), global.Total, global.Failed, found)
+}
+
+// CloseGlobalSearch stops fetching trees for the global search and closes
+// it.
+func (c *AppController) CloseGlobalSearch() {
+	c.stopScan()
+	c.stopGlobalSearch()
+
+	if c.View.GlobalSearchOpen() {
+		c.View.GlobalInput.SetChangedFunc(nil)
+		c.View.HideGlobalSearch()
+	}
+}
+
+// JumpToSelectedResource closes the global search and selects the chosen
+// resource in its application.
+func (c *AppController) JumpToSelectedResource() {
+	index := c.View.GlobalSelection()
+	if index < 0 || index >= len(c.Model.Global.Results) {
+		return
+	}
+	result := c.Model.Global.Results[index]
+
+	c.CloseGlobalSearch()
+	c.Model.SelectResource = &result
+
+	app, ok := c.Model.Application(result.App)
+	if !ok {
+		c.Model.SelectResource = nil
+		c.Notify("Application "+result.App+" is gone", true)
+		return
+	}
+
+	// the search of the applications hides it
+	if !c.Model.AppFilter.Match(search.Application(app)) {
+		c.Model.AppFilter = search.Query{}
+		c.Model.AppLimit = c.View.Config.PageSize
+		c.Model.MoreApps = c.View.UpdateAppTable(c.Model.Applications, c.Model.AppFilter, c.Model.AppLimit)
+	}
+
+	// selecting another application loads its tree, which then selects the
+	// resource
+	selected := c.Model.SelectedAppName == result.App
+	c.RevealApp(result.App)
+	if selected {
+		c.selectPendingResource()
+	}
+
+	c.View.App.SetFocus(c.View.MainTable)
+	c.Model.PrevFocused = c.View.MainTable
+}
+
+// selectPendingResource selects the resource the global search jumped to
+// once the tree of its application is shown.
+func (c *AppController) selectPendingResource() {
+	pending := c.Model.SelectResource
+	if pending == nil {
+		return
+	}
+	if pending.App != c.Model.SelectedAppName {
+		c.Model.SelectResource = nil
+		return
+	}
+
+	if row := c.resourceRow(pending); row >= 0 {
+		c.Model.SelectResource = nil
+		c.View.MainTable.Select(row+1, 0)
+		return
+	}
+
+	// the search of the pane hides it
+	if !c.Model.MainFilter.Empty() {
+		c.Model.MainFilter = search.Query{}
+		c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
+		if row := c.resourceRow(pending); row >= 0 {
+			c.Model.SelectResource = nil
+			c.View.MainTable.Select(row+1, 0)
+		}
+	}
+}
+
+func (c *AppController) resourceRow(result *model.GlobalResult) int {
+	for i, node := range c.FilterContent() {
+		if result.Is(c.Model.SelectedAppName, node) {
+			return i
+		}
+	}
+	return -1
+}
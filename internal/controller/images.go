@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"example.com/main/services/images"
+)
+
+// OpenImages opens the report of the images running in every application.
+// Cached trees are counted right away, the missing and stale ones are
+// fetched in the background like for the global search.
+func (c *AppController) OpenImages() {
+	c.CloseImages()
+
+	scan, requests := c.Model.NewTreeScan()
+	c.Model.ImageScan = scan
+	c.startScan(&c.Model.ImageScan, requests, c.renderImages)
+
+	c.View.ShowImages()
+	c.renderImages()
+}
+
+func (c *AppController) renderImages() {
+	scan := &c.Model.ImageScan
+	c.Model.ImageReport = images.Report(scan.Trees)
+	c.View.UpdateImages(c.Model.ImageReport)
+	c.View.SetImagesStatus(scan.Searched(), scan.Total, scan.Failed, c.Model.ImageReport)
+}
+
+// CloseImages stops fetching trees for the images page and closes it.
+func (c *AppController) CloseImages() {
+	c.stopScan()
+
+	if c.View.ImagesOpen() {
+		c.View.HideImages()
+	}
+}
+
+// ExportImages writes the images report to a timestamped file in the working
+// directory, as CSV or JSON.
+func (c *AppController) ExportImages(format string) {
+	scan := &c.Model.ImageScan
+	if scan.Pending > 0 {
+		c.Notify(fmt.Sprintf("Still searching %d applications, export once the report is complete", scan.Pending), true)
+		return
+	}
+
+	write := images.WriteCSV
+	if format == "json" {
+		write = images.WriteJSON
+	}
+
+	name := fmt.Sprintf("images-%s.%s", time.Now().Format("20060102-150405"), format)
+	path, err := filepath.Abs(name)
+	if err == nil {
+		err = writeFile(path, func(w io.Writer) error {
+			return write(w, c.Model.ImageReport)
+		})
+	}
+	if err != nil {
+		c.Model.Logger.Errorf("Could not export the images: %v", err)
+		c.Notify(fmt.Sprintf("Could not export the images: %v", err), true)
+		return
+	}
+
+	message := fmt.Sprintf("Exported %d images to %s", len(c.Model.ImageReport), path)
+	if scan.Failed > 0 {
+		message += fmt.Sprintf(", %d applications could not be searched", scan.Failed)
+	}
+	c.Notify(message, scan.Failed > 0)
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
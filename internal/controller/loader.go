@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"example.com/main/services/argocd"
+	"example.com/main/services/utils"
+	"github.com/rivo/tview"
+)
+
+const (
+	// navigationDebounce delays fetching a resource tree so scrolling through
+	// the applications does not start a request for every row passed.
+	navigationDebounce = 150 * time.Millisecond
+	spinnerInterval    = 100 * time.Millisecond
+)
+
+// LoadApplications lists the applications in the background and selects the
+// application given on the command line once they arrived. A fresh cached
+// list is used unless force is set. It returns the resourceVersion of the
+// list, empty if it failed.
+func (c *AppController) LoadApplications(ctx context.Context, force bool) string {
+	if entry, fresh, ok := c.Model.ArgoCDService.CachedApplications(); ok && fresh && !force {
+		c.View.App.QueueUpdateDraw(func() {
+			c.setApplications(entry.Value.Items)
+			c.View.SetCacheNote(c.View.AppTable, cacheNote(entry.Fetched, false))
+		})
+		return entry.Version
+	}
+
+	result, err := c.Model.ArgoCDService.ListApplicationsContext(ctx)
+	if ctx.Err() != nil {
+		return ""
+	}
+
+	c.View.App.QueueUpdateDraw(func() {
+		if err != nil {
+			c.Model.Logger.Errorf("Could not get applications: %v", err)
+			if len(c.Model.Applications) == 0 {
+				c.View.ShowPlaceholder(c.View.AppTable, "Could not load applications", c.View.Config.Degraded)
+			}
+			c.Notify(fmt.Sprintf("Could not get applications: %v", err), true)
+			return
+		}
+
+		c.setApplications(result.Items)
+		c.View.SetCacheNote(c.View.AppTable, "")
+	})
+
+	if err != nil {
+		return ""
+	}
+	version, _ := result.Metadata["resourceVersion"].(string)
+	return version
+}
+
+func (c *AppController) setApplications(apps []argocd.ApplicationItem) {
+	c.Model.SetApplications(apps)
+	c.RenderApps()
+
+	if c.Model.InitialApp != "" {
+		if !c.RevealApp(c.Model.InitialApp) {
+			c.Model.Logger.Warnf("Application %q not found", c.Model.InitialApp)
+		}
+		c.Model.InitialApp = ""
+	}
+
+	c.UpdateStatus()
+}
+
+// LoadResources shows the resource tree of name. A cached tree is shown right
+// away and, unless it is still fresh, fetched again in the background. A
+// newer call cancels the request in flight, so a slow response for an
+// application that is no longer selected is never shown. It has to be called
+// on the UI goroutine.
+func (c *AppController) LoadResources(name string) {
+	c.loadResources(name, false)
+}
+
+func (c *AppController) loadResources(name string, force bool) {
+	if c.cancelLoad != nil {
+		c.cancelLoad()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelLoad = cancel
+
+	svc := c.Model.ArgoCDService
+	version := c.Model.AppVersion(name)
+	entry, fresh, cached := svc.CachedResourceTree(name, version)
+
+	c.Model.SelectedAppName = name
+	app, _ := c.Model.Application(name)
+	c.View.ResourceSync = app.ResourceSync()
+
+	if cached {
+		c.Model.SelectedAppResources = entry.Value
+		c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
+		c.View.SetCacheNote(c.View.MainTable, cacheNote(entry.Fetched, !fresh || force))
+		c.selectPendingResource()
+
+		if fresh && !force {
+			cancel()
+			return
+		}
+	} else {
+		c.Model.SelectedAppResources = nil
+		c.View.SetCacheNote(c.View.MainTable, "")
+		c.View.ShowLoading(c.View.MainTable, fmt.Sprintf("Loading %s", tview.Escape(name)), 0)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(navigationDebounce):
+		}
+
+		if !cached {
+			go c.spin(ctx, name)
+		}
+
+		nodes, err := svc.ResourceTree(ctx, name, version)
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.View.App.QueueUpdateDraw(func() {
+			// the selection may have changed while this was queued
+			if ctx.Err() != nil {
+				return
+			}
+			cancel()
+
+			if err != nil {
+				c.Model.Logger.Errorf("Could not get the resources of %s: %v", name, err)
+				c.Notify(err.Error(), true)
+				c.Model.SelectResource = nil
+
+				if cached {
+					c.View.SetCacheNote(c.View.MainTable, cacheNote(entry.Fetched, false)+", refresh failed")
+					return
+				}
+				c.View.ShowPlaceholder(c.View.MainTable, fmt.Sprintf("Could not load %s", tview.Escape(name)), c.View.Config.Degraded)
+				return
+			}
+
+			row, _ := c.View.MainTable.GetSelection()
+			c.Model.SelectedAppResources = nodes
+			c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
+			c.View.SetCacheNote(c.View.MainTable, "")
+
+			if cached {
+				// keep the place when the revalidated tree replaces the cached one
+				c.View.MainTable.Select(min(row, c.View.MainTable.GetRowCount()-1), 0)
+			}
+			c.selectPendingResource()
+		})
+	}()
+}
+
+// Refresh fetches the applications and the selected resource tree again,
+// bypassing the cache.
+func (c *AppController) Refresh() {
+	go c.LoadApplications(context.Background(), true)
+
+	if c.Model.SelectedAppName != "" {
+		c.loadResources(c.Model.SelectedAppName, true)
+	}
+}
+
+func cacheNote(fetched time.Time, refreshing bool) string {
+	note := fmt.Sprintf("cached %s ago", utils.ShortDuration(time.Since(fetched)))
+	if refreshing {
+		note += ", refreshing…"
+	}
+	return note
+}
+
+// spin animates the loading indicator of name until ctx is done.
+func (c *AppController) spin(ctx context.Context, name string) {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+
+	for frame := 1; ; frame++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.View.App.QueueUpdateDraw(func() {
+				if ctx.Err() == nil {
+					c.View.ShowLoading(c.View.MainTable, fmt.Sprintf("Loading %s", tview.Escape(name)), frame)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/argocd"
+)
+
+// scanRenderInterval throttles rendering a page while the trees of a scan
+// arrive.
+const scanRenderInterval = 250 * time.Millisecond
+
+// startScan fetches the trees of requests into scan in the background and
+// calls render as they arrive. Closing the page stops the scan with
+// stopScan.
+func (c *AppController) startScan(scan *model.TreeScan, requests []argocd.TreeRequest, render func()) context.Context {
+	c.stopScan()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelScan = cancel
+
+	go c.Model.ArgoCDService.FetchTrees(ctx, requests, func(request argocd.TreeRequest, nodes []argocd.ApplicationNode, err error) {
+		c.View.App.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				c.Model.Logger.Errorf("Could not get the resources of %s: %v", request.Name, err)
+			}
+			scan.Add(request.Name, nodes, err)
+			c.scheduleRender(ctx, scan, render)
+		})
+	})
+
+	return ctx
+}
+
+// scheduleRender renders soon, once for every tree that arrived meanwhile,
+// or right away when the last tree arrived.
+func (c *AppController) scheduleRender(ctx context.Context, scan *model.TreeScan, render func()) {
+	if scan.Pending == 0 {
+		render()
+		return
+	}
+	if c.scanTimer != nil {
+		return
+	}
+
+	c.scanTimer = time.AfterFunc(scanRenderInterval, func() {
+		c.View.App.QueueUpdateDraw(func() {
+			c.scanTimer = nil
+			if ctx.Err() == nil {
+				render()
+			}
+		})
+	})
+}
+
+func (c *AppController) stopScan() {
+	if c.cancelScan != nil {
+		c.cancelScan()
+		c.cancelScan = nil
+	}
+	if c.scanTimer != nil {
+		c.scanTimer.Stop()
+		c.scanTimer = nil
+	}
+}
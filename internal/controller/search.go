@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"time"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/search"
+)
+
+// searchDebounce is how long typing has to pause before the search is
+// applied to the pane.
+const searchDebounce = 150 * time.Millisecond
+
+// SearchFields returns the qualifiers a query may use in the pane being
+// searched.
+func (c *AppController) SearchFields() []string {
+	switch c.Model.PrevFocused {
+	case c.View.MainTable:
+		return search.ResourceFields
+	case c.View.HelpPage:
+		return model.HelpFields
+	default:
+		return search.AppFields
+	}
+}
+
+// searchContext returns the context of the pane being searched, which keys
+// its search history.
+func (c *AppController) searchContext() model.Context {
+	switch c.Model.PrevFocused {
+	case c.View.MainTable:
+		return model.MainPage
+	case c.View.HelpPage:
+		return model.Help
+	default:
+		return model.AppTable
+	}
+}
+
+// PaneFilter returns the search applied to the pane being searched.
+func (c *AppController) PaneFilter() search.Query {
+	switch c.Model.PrevFocused {
+	case c.View.MainTable:
+		return c.Model.MainFilter
+	case c.View.HelpPage:
+		return c.Model.HelpFilter
+	default:
+		return c.Model.AppFilter
+	}
+}
+
+// ApplySearch filters the pane being searched with query, or highlights its
+// matches in highlight mode.
+func (c *AppController) ApplySearch(query search.Query) {
+	switch c.Model.PrevFocused {
+	case c.View.MainTable:
+		c.Model.MainFilter = query
+		c.View.UpdateMainContent(c.Model.SelectedAppResources, c.Model.MainFilter)
+	case c.View.HelpPage:
+		c.Model.HelpFilter = query
+		c.View.UpdateHelp(c.CommandModel, c.Model.HelpContext, c.Model.HelpFilter)
+	default:
+		c.Model.AppFilter = query
+		c.Model.AppLimit = c.View.Config.PageSize
+		c.Model.MoreApps = c.View.UpdateAppTable(c.Model.Applications, c.Model.AppFilter, c.Model.AppLimit)
+	}
+
+	c.View.SetSearchTitle(query)
+}
+
+// StartSearch sets up the search bar that was just opened: typing searches
+// the pane as soon as the query is valid and Up and Down walk its history.
+func (c *AppController) StartSearch() {
+	c.searchBefore = c.PaneFilter()
+	c.StartHistory(c.searchContext())
+	c.View.SearchInput.SetChangedFunc(c.searchChanged)
+}
+
+// CloseSearch closes the search bar without applying it, the pane goes back
+// to the search it had before.
+func (c *AppController) CloseSearch() {
+	c.stopSearch()
+	c.View.ToggleCommandBar()
+	c.View.App.SetFocus(c.Model.PrevFocused)
+
+	if c.PaneFilter().Text != c.searchBefore.Text {
+		c.ApplySearch(c.searchBefore)
+		return
+	}
+	c.View.SetSearchTitle(c.searchBefore)
+}
+
+func (c *AppController) searchChanged(text string) {
+	query, err := search.Parse(text, c.SearchFields()...)
+	c.View.ShowSearchError(text, err)
+	if err != nil {
+		return
+	}
+
+	c.stopSearch()
+	c.searchTimer = time.AfterFunc(searchDebounce, func() {
+		c.View.App.QueueUpdateDraw(func() {
+			// the search was confirmed, cancelled or changed meanwhile
+			if !c.View.SearchBarOpen() || c.Model.CommandMode || c.View.SearchInput.GetText() != text {
+				return
+			}
+			c.ApplySearch(query)
+		})
+	})
+}
+
+func (c *AppController) stopSearch() {
+	if c.searchTimer != nil {
+		c.searchTimer.Stop()
+	}
+}
+
+// ToggleHighlight switches between filtering the panes and highlighting the
+// matches of their searches, and renders the pane being searched again.
+func (c *AppController) ToggleHighlight() {
+	c.Model.HighlightMatches = !c.Model.HighlightMatches
+	c.View.SetHighlightMode(c.Model.HighlightMatches)
+
+	query := c.PaneFilter()
+	if c.View.SearchBarOpen() && !c.Model.CommandMode {
+		typed, err := search.Parse(c.View.SearchInput.GetText(), c.SearchFields()...)
+		if err != nil {
+			return
+		}
+		query = typed
+	}
+
+	c.ApplySearch(query)
+}
+
+// StartHistory lets Up and Down in the search bar walk the history of ctx.
+func (c *AppController) StartHistory(ctx model.Context) {
+	c.historyContext = ctx
+	c.historyIndex = len(c.Model.SearchHistory[ctx])
+	c.historyDraft = ""
+}
+
+// WalkHistory replaces the text of the search bar with an older entry of the
+// history, or a newer one when dir is 1. Walking past the newest entry
+// brings back what was typed.
+func (c *AppController) WalkHistory(dir int) {
+	history := c.Model.SearchHistory[c.historyContext]
+
+	index := c.historyIndex + dir
+	if index < 0 || index > len(history) {
+		return
+	}
+
+	if c.historyIndex == len(history) {
+		c.historyDraft = c.View.SearchInput.GetText()
+	}
+	c.historyIndex = index
+
+	if index == len(history) {
+		c.View.SearchInput.SetText(c.historyDraft)
+		return
+	}
+	c.View.SearchInput.SetText(history[index])
+}
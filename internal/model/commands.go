@@ -1,9 +1,13 @@
 package model
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
-	"github.com/gdamore/tcell/v2"
+	"example.com/main/services/keys"
+	"example.com/main/services/search"
 )
 
 type Context string
@@ -15,52 +19,137 @@ const (
 	AppTable   = "AppTable"
 	MainPage   = "MainPage"
 	Help       = "Help"
+	Dialog     = "Dialog"
+	Search     = "Search"
+	Images     = "Images"
+	Review     = "Review"
 )
 
+var Contexts = []Context{App, Global, CommandBar, AppTable, MainPage, Help, Dialog, Review, Search, Images}
+
 type Command struct {
+	ID          string
+	Index       int
 	Description string
 	Handler     func()
 	Context     Context
+	Keys        keys.Sequence
+	Mutating    bool
+	// When reports whether the command handles its keys right now, a nil When
+	// always does
+	When func() bool
+	// the keys the action has without a user binding, and whether Keys come
+	// from one
+	defaults keys.Sequence
+	custom   bool
+}
+
+// Enabled reports whether the command handles its keys. Keys of a disabled
+// command fall through to the outer contexts and the focused widget.
+func (c *Command) Enabled() bool {
+	return c.When == nil || c.When()
 }
 
 func (c *Command) String() string {
 	return fmt.Sprintf("%-10s - %s", c.Context, c.Description)
 }
 
-type KeyStroke struct {
-	Rune rune
-	Key  tcell.Key
+// HelpFields are the qualifiers known for the help page.
+var HelpFields = []string{"key", "id", "context"}
+
+// Subject describes the command for queries on the help page. Terms without
+// a qualifier match the keys, the context and the description.
+func (c *Command) Subject() search.Subject {
+	return func(field string) []string {
+		switch field {
+		case "":
+			return []string{c.Keys.Display() + " " + c.String()}
+		case "key":
+			return []string{c.Keys.Display()}
+		case "id":
+			return []string{c.ID}
+		case "context":
+			return []string{string(c.Context)}
+		}
+		return nil
+	}
+}
+
+// BindingError is a user key binding that could not be applied.
+type BindingError struct {
+	Context Context
+	ID      string
+	Message string
+}
+
+func (e *BindingError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("keys.%s: %s", strings.ToLower(string(e.Context)), e.Message)
+	}
+	return fmt.Sprintf("keys.%s.%s: %s", strings.ToLower(string(e.Context)), e.ID, e.Message)
 }
 
 type CommandModel struct {
-	Commands map[Context]map[KeyStroke]*Command
+	Commands map[Context]map[string]*Command
 	Context  Context
+	Bindings map[string]map[string]keys.Sequence
+	Readonly bool
+	added    int
+	declared map[Context]map[string]bool
 }
 
-func NewCommandModel() *CommandModel {
-	commands := map[Context]map[KeyStroke]*Command{}
+// NewCommandModel takes the user key bindings from the config, keyed by
+// lowercase context name and then action ID, which override the defaults
+// passed to Add.
+func NewCommandModel(bindings map[string]map[string]keys.Sequence) *CommandModel {
+	commands := map[Context]map[string]*Command{}
+
+	for _, ctx := range Contexts {
+		commands[ctx] = map[string]*Command{}
+	}
 
-	commands[App] = map[KeyStroke]*Command{}
-	commands[Global] = map[KeyStroke]*Command{}
-	commands[CommandBar] = map[KeyStroke]*Command{}
-	commands[AppTable] = map[KeyStroke]*Command{}
-	commands[MainPage] = map[KeyStroke]*Command{}
-	commands[Help] = map[KeyStroke]*Command{}
+	if bindings == nil {
+		bindings = map[string]map[string]keys.Sequence{}
+	}
 
 	return &CommandModel{
 		Commands: commands,
 		Context:  Global,
+		Bindings: bindings,
+		declared: map[Context]map[string]bool{},
 	}
 }
 
-func (m *CommandModel) Add(ks KeyStroke, context Context, desc string, handler func(ctx Context)) error {
-	if cmd, ok := m.Commands[context][ks]; ok {
-		return fmt.Errorf("error: command already exists, %s", cmd)
+// Add registers an action with its default keys, or the keys the user bound
+// it to. A user binding that collides with another action is reported
+// against the user's entry and the action keeps its default keys, so no
+// default is ever unbound by a user binding.
+func (m *CommandModel) Add(id string, defaultKeys string, context Context, desc string, handler func(ctx Context)) error {
+	if m.declared[context] == nil {
+		m.declared[context] = map[string]bool{}
+	}
+	m.declared[context][id] = true
+
+	if existing := m.Get(context, id); existing != nil {
+		return fmt.Errorf("action %q already exists in %s", id, context)
 	}
 
-	cmd := Command{
+	defaults := keys.MustParse(defaultKeys)
+	seq, custom := m.Bindings[strings.ToLower(string(context))][id]
+	if !custom {
+		seq = defaults
+	}
+
+	m.added++
+
+	cmd := &Command{
+		ID:          id,
+		Index:       m.added,
 		Context:     context,
 		Description: desc,
+		Keys:        seq,
+		defaults:    defaults,
+		custom:      custom,
 		Handler: func() {
 			handler(context)
 			if context != Global {
@@ -69,6 +158,135 @@ func (m *CommandModel) Add(ks KeyStroke, context Context, desc string, handler f
 		},
 	}
 
-	m.Commands[context][ks] = &cmd
+	return m.bind(cmd)
+}
+
+// bind puts cmd on its keys. When the keys are taken, the user binding
+// involved gives way and its action falls back to its default keys.
+func (m *CommandModel) bind(cmd *Command) error {
+	other, taken := m.Commands[cmd.Context][cmd.Keys.String()]
+	if !taken {
+		m.Commands[cmd.Context][cmd.Keys.String()] = cmd
+		return nil
+	}
+
+	conflict := &BindingError{
+		Context: cmd.Context,
+		ID:      cmd.ID,
+		Message: fmt.Sprintf("%q is already bound to %q", cmd.Keys, other.ID),
+	}
+
+	switch {
+	case cmd.custom:
+		cmd.Keys, cmd.custom = cmd.defaults, false
+		return errors.Join(conflict, m.bind(cmd))
+	case other.custom:
+		// the user bound another action to the default keys of cmd
+		conflict.ID = other.ID
+		conflict.Message = fmt.Sprintf("%q is already bound to %q", cmd.Keys, cmd.ID)
+
+		m.Commands[cmd.Context][cmd.Keys.String()] = cmd
+		other.Keys, other.custom = other.defaults, false
+		return errors.Join(conflict, m.bind(other))
+	}
+
+	return conflict
+}
+
+// AddMutating adds a command that changes the cluster. In read-only mode it
+// is still bound, so the key can be refused, but hidden from the help page.
+func (m *CommandModel) AddMutating(id string, defaultKeys string, context Context, desc string, handler func(ctx Context)) error {
+	err := m.Add(id, defaultKeys, context, desc, handler)
+	if err != nil {
+		return err
+	}
+
+	m.Get(context, id).Mutating = true
 	return nil
 }
+
+// AddWhen adds a command that only handles its keys while when reports true,
+// like clearing a search only while there is one.
+func (m *CommandModel) AddWhen(id string, defaultKeys string, context Context, desc string, when func() bool, handler func(ctx Context)) error {
+	err := m.Add(id, defaultKeys, context, desc, handler)
+	if err != nil {
+		return err
+	}
+
+	m.Get(context, id).When = when
+	return nil
+}
+
+// Hidden reports whether cmd is left out of the help page.
+func (m *CommandModel) Hidden(cmd *Command) bool {
+	return m.Readonly && cmd.Mutating
+}
+
+func (m *CommandModel) Get(context Context, id string) *Command {
+	for _, cmd := range m.Commands[context] {
+		if cmd.ID == id {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// Sorted returns the commands of a context in the order they were added.
+func (m *CommandModel) Sorted(context Context) []*Command {
+	cmds := make([]*Command, 0, len(m.Commands[context]))
+	for _, cmd := range m.Commands[context] {
+		cmds = append(cmds, cmd)
+	}
+
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Index < cmds[j].Index
+	})
+
+	return cmds
+}
+
+func (m *CommandModel) Lookup(context Context, seq keys.Sequence) (*Command, bool) {
+	cmd, ok := m.Commands[context][seq.String()]
+	return cmd, ok
+}
+
+// Validate reports user bindings that do not refer to a registered action,
+// so typos in the config are not silently ignored.
+func (m *CommandModel) Validate() error {
+	errs := []error{}
+
+	ctxNames := make([]string, 0, len(m.Bindings))
+	for ctxName := range m.Bindings {
+		ctxNames = append(ctxNames, ctxName)
+	}
+	sort.Strings(ctxNames)
+
+	for _, ctxName := range ctxNames {
+		var context Context
+		for _, ctx := range Contexts {
+			if strings.ToLower(string(ctx)) == ctxName {
+				context = ctx
+			}
+		}
+
+		if context == "" {
+			errs = append(errs, &BindingError{Context: Context(ctxName), Message: "unknown context"})
+			continue
+		}
+
+		ids := make([]string, 0, len(m.Bindings[ctxName]))
+		for id := range m.Bindings[ctxName] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			if !m.declared[context][id] {
+				errs = append(errs, &BindingError{Context: context, ID: id, Message: "unknown action"})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"example.com/main/services/argocd"
+	"example.com/main/services/search"
+)
+
+// GlobalResult is a resource found by the global search.
+type GlobalResult struct {
+	App  string
+	Node argocd.ApplicationNode
+	Sync argocd.ApplicationSyncStatus
+}
+
+// Is reports whether node is the resource that was found, also in a tree
+// fetched again since.
+func (r GlobalResult) Is(app string, node argocd.ApplicationNode) bool {
+	return r.App == app &&
+		r.Node.Kind == node.Kind &&
+		r.Node.Namespace == node.Namespace &&
+		r.Node.Name == node.Name
+}
+
+// GlobalSearch is the state of the search across the resources of every
+// application.
+type GlobalSearch struct {
+	TreeScan
+	Filter  search.Query
+	Results []GlobalResult
+}
+
+// Find updates the results with the resources matching the filter, in the
+// order of apps.
+func (g *GlobalSearch) Find(apps []argocd.ApplicationItem) {
+	g.Results = nil
+	if g.Filter.Empty() {
+		return
+	}
+
+	all := []GlobalResult{}
+	for _, app := range apps {
+		sync := app.ResourceSync()
+		for _, node := range g.Trees[app.Metadata.Name] {
+			all = append(all, GlobalResult{
+				App:  app.Metadata.Name,
+				Node: node,
+				Sync: sync[argocd.ResourceKey(node.Group, node.Kind, node.Namespace, node.Name)],
+			})
+		}
+	}
+
+	g.Results = search.Rank(all, g.Filter, func(result GlobalResult) search.Subject {
+		return search.GlobalResource(result.App, result.Node)
+	})
+}
@@ -1,7 +1,12 @@
 package model
 
 import (
+	"slices"
+	"time"
+
 	"example.com/main/services/argocd"
+	"example.com/main/services/images"
+	"example.com/main/services/search"
 	"github.com/rivo/tview"
 	"github.com/sirupsen/logrus"
 )
@@ -12,9 +17,23 @@ type AppModel struct {
 	Applications         []argocd.ApplicationItem
 	PrevFocused          tview.Primitive
 	SelectedAppName      string
-	MainFilter           string
-	AppFilter            string
-	HelpFilter           string
+	InitialApp           string
+	ContextName          string
+	Protected            bool
+	MainFilter           search.Query
+	AppFilter            search.Query
+	AppLimit             int
+	MoreApps             bool
+	HelpFilter           search.Query
+	HelpContext          Context
+	HighlightMatches     bool
+	SearchHistory        map[Context][]string
+	Global               GlobalSearch
+	SelectResource       *GlobalResult
+	ImageScan            TreeScan
+	ImageReport          []images.Usage
+	CommandMode          bool
+	Status               Status
 	SelectedAppResources []argocd.ApplicationNode
 	ScrollOffset         int
 	PrevIndex            int
@@ -26,16 +45,53 @@ func NewAppModel(logger *logrus.Logger, svc *argocd.Service) *AppModel {
 		ArgoCDService: svc,
 		Logger:        logger,
 		PrevIndex:     0,
+		SearchHistory: map[Context][]string{},
+	}
+}
+
+// searchHistoryLimit is how many searches are remembered per pane.
+const searchHistoryLimit = 50
+
+// AddSearchHistory remembers text as the latest search of the pane ctx. A
+// search repeated right away is only remembered once.
+func (m *AppModel) AddSearchHistory(ctx Context, text string) {
+	history := m.SearchHistory[ctx]
+	if text == "" || (len(history) > 0 && history[len(history)-1] == text) {
+		return
+	}
+
+	history = append(history, text)
+	if len(history) > searchHistoryLimit {
+		history = history[len(history)-searchHistoryLimit:]
 	}
+	m.SearchHistory[ctx] = history
+}
+
+// AppVersion returns the resourceVersion of the named application, which
+// tells whether a cached resource tree may be outdated.
+func (m *AppModel) AppVersion(name string) string {
+	app, _ := m.Application(name)
+	return app.Metadata.ResourceVersion
 }
 
-func (m *AppModel) LoadApplications() {
-	result := m.ArgoCDService.ListApplications()
-	m.Applications = result.Items
-	m.PrevText = result.Items[0].Metadata.Name
+// Application returns the named application.
+func (m *AppModel) Application(name string) (argocd.ApplicationItem, bool) {
+	for _, app := range m.Applications {
+		if app.Metadata.Name == name {
+			return app, true
+		}
+	}
+
+	return argocd.ApplicationItem{}, false
 }
 
-func (m *AppModel) LoadResources(appName string) {
-	m.SelectedAppName = appName
-	m.SelectedAppResources = m.ArgoCDService.GetResourceTree(m.SelectedAppName)
+// SetApplications stores a copy of the applications listed in the
+// background, so the cached response is never changed through the model. The
+// model is only changed on the UI goroutine.
+func (m *AppModel) SetApplications(apps []argocd.ApplicationItem) {
+	m.Applications = slices.Clone(apps)
+	m.Status.LastUpdate = time.Now()
+	if len(apps) > 0 {
+		m.PrevText = apps[0].Metadata.Name
+	}
 }
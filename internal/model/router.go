@@ -0,0 +1,131 @@
+package model
+
+import (
+	"time"
+
+	"example.com/main/services/keys"
+)
+
+const DefaultSequenceTimeout = time.Second
+
+// parents describes the fallback chain of every context. A key that is not
+// bound in the focused context is looked up in its parent, and so on up to
+// App. AppTable and MainPage are siblings, the keys of one pane never act on
+// the selection of the other. CommandBar, Dialog and Search skip Global so typing into an input never
+// triggers single letter commands, Images skips it so its table scrolls on its
+// own. Review is the dialog showing the changes of an edit, its keys only
+// apply there and not to the other dialogs.
+var parents = map[Context]Context{
+	AppTable:   Global,
+	MainPage:   Global,
+	Help:       Global,
+	Global:     App,
+	CommandBar: App,
+	Dialog:     App,
+	Review:     Dialog,
+	Search:     App,
+	Images:     App,
+}
+
+func Stack(ctx Context) []Context {
+	stack := []Context{}
+
+	for ctx != "" {
+		stack = append(stack, ctx)
+		ctx = parents[ctx]
+	}
+
+	return stack
+}
+
+type KeyRouter struct {
+	Commands *CommandModel
+	Timeout  time.Duration
+	Pending  keys.Sequence
+	LastKey  time.Time
+}
+
+func NewKeyRouter(commands *CommandModel) *KeyRouter {
+	return &KeyRouter{
+		Commands: commands,
+		Timeout:  DefaultSequenceTimeout,
+	}
+}
+
+// Dispatch feeds one stroke to the router. It returns the command to run, if
+// any, and whether the stroke was consumed. A stroke that starts or continues
+// a longer binding is held as pending and consumed without a command, when
+// the pending sequence also matches a binding on its own it fires from Expire
+// once the timeout passes.
+func (r *KeyRouter) Dispatch(stack []Context, stroke keys.Stroke, now time.Time) (*Command, bool) {
+	if len(r.Pending) > 0 && now.Sub(r.LastKey) > r.Timeout {
+		r.Pending = nil
+	}
+
+	seq := append(append(keys.Sequence{}, r.Pending...), stroke)
+	cmd, longer := r.resolve(stack, seq)
+
+	if longer {
+		r.Pending = seq
+		r.LastKey = now
+		return nil, true
+	}
+
+	r.Pending = nil
+
+	if cmd != nil {
+		return cmd, true
+	}
+
+	// the stroke broke a pending sequence, so try it on its own
+	if len(seq) > 1 {
+		return r.Dispatch(stack, stroke, now)
+	}
+
+	return nil, false
+}
+
+// Expire resolves a pending sequence once the timeout has passed, returning
+// the command bound to exactly that sequence if there is one.
+func (r *KeyRouter) Expire(stack []Context, now time.Time) *Command {
+	if len(r.Pending) == 0 || now.Sub(r.LastKey) < r.Timeout {
+		return nil
+	}
+
+	pending := r.Pending
+	r.Pending = nil
+
+	for _, ctx := range stack {
+		if cmd, ok := r.Commands.Lookup(ctx, pending); ok && cmd.Enabled() {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// resolve walks the stack from the innermost context outwards and stops at
+// the first context that binds seq or a longer sequence starting with seq, so
+// inner contexts shadow outer ones. Disabled commands do not shadow.
+func (r *KeyRouter) resolve(stack []Context, seq keys.Sequence) (*Command, bool) {
+	for _, ctx := range stack {
+		cmd, _ := r.Commands.Lookup(ctx, seq)
+		if cmd != nil && !cmd.Enabled() {
+			cmd = nil
+		}
+
+		longer := false
+		for _, other := range r.Commands.Commands[ctx] {
+			if len(other.Keys) > len(seq) && other.Keys.HasPrefix(seq) {
+				longer = true
+				break
+			}
+		}
+
+		if cmd != nil || longer {
+			return cmd, longer
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,242 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"example.com/main/services/keys"
+)
+
+type binding struct {
+	id      string
+	keys    string
+	context Context
+}
+
+// newTestRouter registers bindings whose handlers append their context and
+// ID to fired.
+func newTestRouter(t *testing.T, bindings ...binding) (*KeyRouter, *[]string) {
+	t.Helper()
+
+	fired := []string{}
+	commands := NewCommandModel(nil)
+	for _, b := range bindings {
+		err := commands.Add(b.id, b.keys, b.context, b.id, func(ctx Context) {
+			fired = append(fired, string(ctx)+"."+b.id)
+		})
+		if err != nil {
+			t.Fatalf("Add(%q): %v", b.id, err)
+		}
+	}
+
+	return NewKeyRouter(commands), &fired
+}
+
+func press(t *testing.T, r *KeyRouter, ctx Context, spec string, now time.Time) *Command {
+	t.Helper()
+
+	var cmd *Command
+	for _, stroke := range keys.MustParse(spec) {
+		cmd, _ = r.Dispatch(Stack(ctx), stroke, now)
+	}
+	return cmd
+}
+
+func TestStack(t *testing.T) {
+	tests := []struct {
+		ctx  Context
+		want []Context
+	}{
+		{AppTable, []Context{AppTable, Global, App}},
+		{MainPage, []Context{MainPage, Global, App}},
+		{Help, []Context{Help, Global, App}},
+		{CommandBar, []Context{CommandBar, App}},
+		{Dialog, []Context{Dialog, App}},
+		{Search, []Context{Search, App}},
+		{Images, []Context{Images, App}},
+	}
+
+	for _, tt := range tests {
+		if got := Stack(tt.ctx); !slices.Equal(got, tt.want) {
+			t.Errorf("Stack(%s) = %v, want %v", tt.ctx, got, tt.want)
+		}
+	}
+}
+
+func TestDispatchInnerContextShadowsOuter(t *testing.T) {
+	r, _ := newTestRouter(t,
+		binding{"close", "esc", Global},
+		binding{"clear", "esc", AppTable},
+	)
+
+	cmd := press(t, r, AppTable, "esc", time.Now())
+	if cmd == nil || cmd.ID != "clear" || cmd.Context != AppTable {
+		t.Fatalf("esc in AppTable resolved to %v, want AppTable clear", cmd)
+	}
+
+	cmd = press(t, r, MainPage, "esc", time.Now())
+	if cmd == nil || cmd.ID != "close" || cmd.Context != Global {
+		t.Fatalf("esc in MainPage resolved to %v, want Global close", cmd)
+	}
+}
+
+func TestDispatchFallbackOrder(t *testing.T) {
+	r, _ := newTestRouter(t,
+		binding{"table-a", "a", AppTable},
+		binding{"page-a", "a", MainPage},
+		binding{"page-b", "b", MainPage},
+		binding{"global-b", "b", Global},
+		binding{"global-c", "c", Global},
+		binding{"app-c", "c", App},
+		binding{"app-d", "d", App},
+		binding{"page-e", "e", MainPage},
+	)
+
+	tests := []struct {
+		ctx     Context
+		key     string
+		context Context
+	}{
+		{AppTable, "a", AppTable},
+		{AppTable, "b", Global},
+		{AppTable, "c", Global},
+		{AppTable, "d", App},
+		{MainPage, "a", MainPage},
+		{MainPage, "b", MainPage},
+		{MainPage, "c", Global},
+		{MainPage, "d", App},
+	}
+
+	for _, tt := range tests {
+		cmd := press(t, r, tt.ctx, tt.key, time.Now())
+		if cmd == nil || cmd.Context != tt.context {
+			t.Errorf("%s in %s resolved to %v, want the %s binding", tt.key, tt.ctx, cmd, tt.context)
+		}
+	}
+
+	// the resource pane keys do not reach the application list
+	for _, key := range []string{"e", "x"} {
+		if cmd := press(t, r, AppTable, key, time.Now()); cmd != nil {
+			t.Errorf("%s in AppTable resolved to %v, want nothing", key, cmd)
+		}
+	}
+}
+
+func TestDispatchSkipsGlobalForInputs(t *testing.T) {
+	r, _ := newTestRouter(t,
+		binding{"quit", "q", Global},
+		binding{"suspend", "ctrl+z", App},
+	)
+
+	for _, ctx := range []Context{CommandBar, Dialog, Search, Images} {
+		cmd, consumed := r.Dispatch(Stack(ctx), keys.MustParse("q")[0], time.Now())
+		if cmd != nil || consumed {
+			t.Errorf("q in %s resolved to %v, consumed %v, want it passed on", ctx, cmd, consumed)
+		}
+
+		cmd = press(t, r, ctx, "ctrl+z", time.Now())
+		if cmd == nil || cmd.ID != "suspend" {
+			t.Errorf("ctrl+z in %s resolved to %v, want App suspend", ctx, cmd)
+		}
+	}
+}
+
+func TestExpireFiresShorterBinding(t *testing.T) {
+	r, fired := newTestRouter(t,
+		binding{"detail", "d", MainPage},
+		binding{"delete", "d d", MainPage},
+	)
+	stack := Stack(MainPage)
+	start := time.Now()
+
+	cmd, consumed := r.Dispatch(stack, keys.MustParse("d")[0], start)
+	if cmd != nil || !consumed {
+		t.Fatalf("first d resolved to %v, consumed %v, want it held as pending", cmd, consumed)
+	}
+
+	if cmd := r.Expire(stack, start.Add(r.Timeout/2)); cmd != nil {
+		t.Fatalf("Expire before the timeout fired %v", cmd)
+	}
+
+	cmd = r.Expire(stack, start.Add(r.Timeout))
+	if cmd == nil || cmd.ID != "detail" {
+		t.Fatalf("Expire after the timeout fired %v, want detail", cmd)
+	}
+	cmd.Handler()
+
+	if len(r.Pending) != 0 {
+		t.Errorf("pending sequence %v left after Expire", r.Pending)
+	}
+	if !slices.Equal(*fired, []string{"MainPage.detail"}) {
+		t.Errorf("fired %v, want MainPage.detail", *fired)
+	}
+
+	// completed in time the sequence wins
+	now := start.Add(2 * r.Timeout)
+	r.Dispatch(stack, keys.MustParse("d")[0], now)
+	cmd, _ = r.Dispatch(stack, keys.MustParse("d")[0], now.Add(r.Timeout/2))
+	if cmd == nil || cmd.ID != "delete" {
+		t.Errorf("d d resolved to %v, want delete", cmd)
+	}
+}
+
+func TestDispatchBrokenSequence(t *testing.T) {
+	r, _ := newTestRouter(t,
+		binding{"top", "g g", Global},
+		binding{"down", "j", Global},
+	)
+	stack := Stack(MainPage)
+	now := time.Now()
+
+	r.Dispatch(stack, keys.MustParse("g")[0], now)
+	cmd, consumed := r.Dispatch(stack, keys.MustParse("j")[0], now)
+	if cmd == nil || cmd.ID != "down" || !consumed {
+		t.Fatalf("g j resolved to %v, consumed %v, want down", cmd, consumed)
+	}
+	if len(r.Pending) != 0 {
+		t.Errorf("pending sequence %v left after a broken sequence", r.Pending)
+	}
+
+	// an unbound last key is passed on
+	r.Dispatch(stack, keys.MustParse("g")[0], now)
+	cmd, consumed = r.Dispatch(stack, keys.MustParse("x")[0], now)
+	if cmd != nil || consumed {
+		t.Errorf("g x resolved to %v, consumed %v, want x passed on", cmd, consumed)
+	}
+
+	// a pending sequence older than the timeout is dropped
+	r.Dispatch(stack, keys.MustParse("g")[0], now)
+	cmd, _ = r.Dispatch(stack, keys.MustParse("g")[0], now.Add(2*r.Timeout))
+	if cmd != nil || len(r.Pending) != 1 {
+		t.Errorf("late second g resolved to %v with pending %v, want a new pending g", cmd, r.Pending)
+	}
+}
+
+func TestDispatchSkipsDisabledCommands(t *testing.T) {
+	r, _ := newTestRouter(t, binding{"close", "esc", App})
+
+	active := false
+	err := r.Commands.AddWhen("clear", "esc", Global, "clear", func() bool { return active }, func(Context) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := press(t, r, MainPage, "esc", time.Now())
+	if cmd == nil || cmd.ID != "close" {
+		t.Errorf("esc with clear disabled resolved to %v, want App close", cmd)
+	}
+
+	active = true
+	cmd = press(t, r, MainPage, "esc", time.Now())
+	if cmd == nil || cmd.ID != "clear" {
+		t.Errorf("esc with clear enabled resolved to %v, want Global clear", cmd)
+	}
+
+	// with nothing else bound the key is passed on
+	r, _ = newTestRouter(t)
+	r.Commands.AddWhen("clear", "esc", Global, "clear", func() bool { return false }, func(Context) {})
+	if cmd, consumed := r.Dispatch(Stack(MainPage), keys.MustParse("esc")[0], time.Now()); cmd != nil || consumed {
+		t.Errorf("esc resolved to %v, consumed %v, want it passed on", cmd, consumed)
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import "example.com/main/services/argocd"
+
+// TreeScan collects the resource trees of every application for the pages
+// that look across applications. Trees fills up while the trees are fetched,
+// Pending of Total applications are still to be fetched.
+type TreeScan struct {
+	Trees   map[string][]argocd.ApplicationNode
+	Total   int
+	Pending int
+	Failed  int
+}
+
+// NewTreeScan starts a scan with the cached trees and returns the trees that
+// are missing or outdated, to be fetched.
+func (m *AppModel) NewTreeScan() (TreeScan, []argocd.TreeRequest) {
+	scan := TreeScan{
+		Trees: map[string][]argocd.ApplicationNode{},
+		Total: len(m.Applications),
+	}
+
+	requests := []argocd.TreeRequest{}
+	for _, app := range m.Applications {
+		request := argocd.TreeRequest{Name: app.Metadata.Name, Version: app.Metadata.ResourceVersion}
+
+		entry, fresh, cached := m.ArgoCDService.CachedResourceTree(request.Name, request.Version)
+		if cached {
+			scan.Trees[request.Name] = entry.Value
+		}
+		if !fresh {
+			requests = append(requests, request)
+		}
+	}
+	scan.Pending = len(requests)
+
+	return scan, requests
+}
+
+// Add records the tree of name, or that it could not be fetched.
+func (s *TreeScan) Add(name string, nodes []argocd.ApplicationNode, err error) {
+	s.Pending--
+	if err != nil {
+		s.Failed++
+		return
+	}
+	s.Trees[name] = nodes
+}
+
+// Searched is how many applications were fetched so far.
+func (s *TreeScan) Searched() int {
+	return s.Total - s.Pending
+}
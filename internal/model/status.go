@@ -0,0 +1,58 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/main/services/argocd"
+)
+
+type WatchState string
+
+const (
+	WatchConnecting   WatchState = "connecting"
+	WatchLive         WatchState = "live"
+	WatchReconnecting WatchState = "reconnecting"
+)
+
+// Status is what the status bar shows. Message is a transient note from a
+// background operation and is cleared once MessageExpires passed.
+type Status struct {
+	Server         string
+	Context        string
+	User           string
+	Version        string
+	Watch          WatchState
+	LastUpdate     time.Time
+	Message        string
+	MessageIsError bool
+	MessageExpires time.Time
+}
+
+// ApplyWatchEvents updates the applications with changes from the watch
+// stream, keeping them sorted by name. The list is replaced, not edited, as
+// it may still be shared with the cached response it was listed from.
+func (m *AppModel) ApplyWatchEvents(events []argocd.ApplicationWatchEvent) {
+	apps := slices.Clone(m.Applications)
+
+	for _, event := range events {
+		name := event.Application.Metadata.Name
+		index, found := slices.BinarySearchFunc(apps, name, func(app argocd.ApplicationItem, name string) int {
+			return strings.Compare(app.Metadata.Name, name)
+		})
+
+		switch {
+		case event.Type == argocd.WatchDeleted && found:
+			apps = slices.Delete(apps, index, index+1)
+		case event.Type == argocd.WatchDeleted:
+		case found:
+			apps[index] = event.Application
+		default:
+			apps = slices.Insert(apps, index, event.Application)
+		}
+	}
+
+	m.Applications = apps
+	m.Status.LastUpdate = time.Now()
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"example.com/main/services/argocd"
+)
+
+func app(name string, version string) argocd.ApplicationItem {
+	return argocd.ApplicationItem{Metadata: argocd.ApplicationMetadata{Name: name, ResourceVersion: version}}
+}
+
+func names(apps []argocd.ApplicationItem) []string {
+	result := []string{}
+	for _, app := range apps {
+		result = append(result, app.Metadata.Name+"@"+app.Metadata.ResourceVersion)
+	}
+	return result
+}
+
+func TestApplyWatchEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		event argocd.ApplicationWatchEvent
+		want  []string
+	}{
+		{"delete first", argocd.ApplicationWatchEvent{Type: argocd.WatchDeleted, Application: app("a", "")}, []string{"b@1", "c@1"}},
+		{"delete last", argocd.ApplicationWatchEvent{Type: argocd.WatchDeleted, Application: app("c", "")}, []string{"a@1", "b@1"}},
+		{"delete unknown", argocd.ApplicationWatchEvent{Type: argocd.WatchDeleted, Application: app("x", "")}, []string{"a@1", "b@1", "c@1"}},
+		{"modify", argocd.ApplicationWatchEvent{Type: argocd.WatchModified, Application: app("b", "2")}, []string{"a@1", "b@2", "c@1"}},
+		{"add known", argocd.ApplicationWatchEvent{Type: argocd.WatchAdded, Application: app("a", "2")}, []string{"a@2", "b@1", "c@1"}},
+		{"add between", argocd.ApplicationWatchEvent{Type: argocd.WatchAdded, Application: app("bb", "1")}, []string{"a@1", "b@1", "bb@1", "c@1"}},
+		{"add first", argocd.ApplicationWatchEvent{Type: argocd.WatchAdded, Application: app("0", "1")}, []string{"0@1", "a@1", "b@1", "c@1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := argocd.NewCache[argocd.ListApplicationsResponse](argocd.DefaultCacheTTL, 1)
+			cache.Put("applications", "1", argocd.ListApplicationsResponse{
+				Items: []argocd.ApplicationItem{app("a", "1"), app("b", "1"), app("c", "1")},
+			})
+
+			entry, _ := cache.Get("applications")
+			m := NewAppModel(nil, nil)
+			m.SetApplications(entry.Value.Items)
+			m.ApplyWatchEvents([]argocd.ApplicationWatchEvent{tt.event})
+
+			if got := names(m.Applications); !slices.Equal(got, tt.want) {
+				t.Errorf("applications = %v, want %v", got, tt.want)
+			}
+
+			// the cached list must not change with the model
+			entry, _ = cache.Get("applications")
+			if got, want := names(entry.Value.Items), []string{"a@1", "b@1", "c@1"}; !slices.Equal(got, want) {
+				t.Errorf("cached applications = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyWatchEventsKeepsSharedList(t *testing.T) {
+	listed := []argocd.ApplicationItem{app("a", "1"), app("b", "1"), app("c", "1")}
+
+	// without SetApplications the model shares the array of listed
+	m := &AppModel{Applications: listed[:2]}
+	m.ApplyWatchEvents([]argocd.ApplicationWatchEvent{
+		{Type: argocd.WatchAdded, Application: app("ab", "1")},
+		{Type: argocd.WatchDeleted, Application: app("a", "")},
+	})
+
+	if got, want := names(listed), []string{"a@1", "b@1", "c@1"}; !slices.Equal(got, want) {
+		t.Errorf("listed = %v, want %v", got, want)
+	}
+	if got, want := names(m.Applications), []string{"ab@1", "b@1"}; !slices.Equal(got, want) {
+		t.Errorf("applications = %v, want %v", got, want)
+	}
+}
+
+func TestApplyWatchEventsInOrder(t *testing.T) {
+	m := &AppModel{Applications: []argocd.ApplicationItem{app("a", "1")}}
+	m.ApplyWatchEvents([]argocd.ApplicationWatchEvent{
+		{Type: argocd.WatchAdded, Application: app("b", "1")},
+		{Type: argocd.WatchModified, Application: app("b", "2")},
+		{Type: argocd.WatchDeleted, Application: app("a", "")},
+		{Type: argocd.WatchAdded, Application: app("a", "3")},
+		{Type: argocd.WatchDeleted, Application: app("b", "")},
+	})
+
+	if got, want := names(m.Applications), []string{"a@3"}; !slices.Equal(got, want) {
+		t.Errorf("applications = %v, want %v", got, want)
+	}
+}
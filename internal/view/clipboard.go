@@ -0,0 +1,53 @@
+package view
+
+import (
+	"errors"
+	"strings"
+
+	"example.com/main/services/utils"
+	"github.com/rivo/tview"
+)
+
+// Copy writes the OSC 52 sequence for text to Terminal, if enabled. The
+// screen is suspended meanwhile, so the sequence never gets between the
+// output of tcell. It has to be called on the UI goroutine.
+func (v *AppView) Copy(text string) error {
+	if !v.Config.Clipboard.OSC52 {
+		return nil
+	}
+
+	var err error
+	if !v.App.Suspend(func() {
+		err = v.Config.Clipboard.Copy(v.Terminal, text)
+	}) {
+		return errors.New("the screen could not be suspended")
+	}
+
+	return err
+}
+
+// TableTSV returns the rows of table as tab separated values, the text as it
+// is shown without the sort marker of the first headers rows.
+func (v *AppView) TableTSV(table *tview.Table, headers int) string {
+	b := &strings.Builder{}
+	for row := 0; row < table.GetRowCount(); row++ {
+		cells := make([]string, table.GetColumnCount())
+		for column := range cells {
+			cell := table.GetCell(row, column)
+			if cell == nil {
+				continue
+			}
+
+			text := utils.StripTags(cell.Text)
+			if row < headers {
+				text = strings.TrimSuffix(strings.TrimSuffix(text, " ▲"), " ▼")
+			}
+			cells[column] = strings.Join(strings.Fields(text), " ")
+		}
+
+		b.WriteString(strings.Join(cells, "\t"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package view
+
+import (
+	"example.com/main/services/columns"
+	"example.com/main/services/search"
+	"github.com/rivo/tview"
+)
+
+// setColumnHeaders writes the header row of a table of resources, the fixed
+// headers first. The sorted column shows its direction.
+func (v *AppView) setColumnHeaders(table *tview.Table, fixed []string, configured []columns.Column, sort columns.Sort) {
+	headers := append([]string{}, fixed...)
+	for _, column := range configured {
+		header := column.Name
+		switch {
+		case column.Name != sort.Column:
+		case sort.Descending:
+			header += " ▼"
+		default:
+			header += " ▲"
+		}
+		headers = append(headers, header)
+	}
+
+	for i, header := range headers {
+		cell := tview.NewTableCell(tview.Escape(header)).
+			SetTextColor(v.Config.Header).
+			SetAlign(tview.AlignLeft)
+		if i >= len(fixed) && columns.Numeric(configured[i-len(fixed)].Name) {
+			cell.SetAlign(tview.AlignRight)
+		}
+		table.SetCell(0, i, cell)
+	}
+	table.SetFixed(1, 0)
+}
+
+// setResourceCells writes the configured columns of resource into row of
+// table from column first on, with the matches of filter highlighted unless
+// the row does not match.
+func (v *AppView) setResourceCells(table *tview.Table, row int, first int, configured []columns.Column, resource columns.Resource, filter search.Query, matched bool) {
+	for i, column := range configured {
+		value, field := resource.Value(column.Name)
+
+		text := tview.Escape(value)
+		if field != "" && matched {
+			text = highlight(value, filter.Matches(value, field))
+		}
+
+		cell := v.stateCell(text, resource.Node.Health.Status).
+			SetMaxWidth(column.Width)
+		if columns.Numeric(column.Name) {
+			cell.SetAlign(tview.AlignRight)
+		}
+		if i == len(configured)-1 {
+			cell.SetExpansion(1)
+		}
+
+		table.SetCell(row, first+i, cell)
+	}
+}
+
+func resourceSubject(resource columns.Resource) search.Subject {
+	return search.Resource(resource.Node)
+}
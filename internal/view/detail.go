@@ -0,0 +1,189 @@
+package view
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"example.com/main/services/argocd"
+	"example.com/main/services/config"
+	"example.com/main/services/search"
+	"example.com/main/services/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// detailSideBySide is the width of the main content from which the resource
+// detail opens beside the table, narrower terminals get it below the table.
+const detailSideBySide = 110
+
+func newResourceDetail(config *config.Config) *tview.TextView {
+	detail := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true).
+		SetWordWrap(true)
+
+	detail.
+		SetBorder(true).
+		SetBorderColor(config.Border).
+		SetTitle(" Details ")
+
+	return detail
+}
+
+// ToggleDetail shows or hides the detail of the selected resource and
+// returns whether it is shown.
+func (v *AppView) ToggleDetail() bool {
+	if v.DetailShown() {
+		v.MainContentContainer.RemoveItem(v.ResourceDetail)
+		return false
+	}
+
+	v.MainContentContainer.AddItem(v.ResourceDetail, 0, 1, false)
+	v.updateDetail()
+	return true
+}
+
+func (v *AppView) DetailShown() bool {
+	return v.MainContentContainer.GetItemCount() > 1
+}
+
+// layoutDetail puts the detail beside the table when the main content is
+// at least detailSideBySide wide, otherwise below it.
+func (v *AppView) layoutDetail(width int) {
+	if !v.DetailShown() {
+		return
+	}
+
+	if width >= detailSideBySide {
+		v.MainContentContainer.SetDirection(tview.FlexColumn)
+		v.MainContentContainer.ResizeItem(v.ResourceDetail, min(70, width*2/5), 0)
+		return
+	}
+
+	v.MainContentContainer.SetDirection(tview.FlexRow)
+	v.MainContentContainer.ResizeItem(v.ResourceDetail, 0, 1)
+}
+
+// ShownResources returns the resources in the rows of the main table, in
+// the order they are shown.
+func (v *AppView) ShownResources() []argocd.ApplicationNode {
+	return v.shownResources
+}
+
+// updateDetail shows the resource of the selected row of the main table.
+func (v *AppView) updateDetail() {
+	if !v.DetailShown() {
+		return
+	}
+
+	v.ResourceDetail.Clear()
+	v.ResourceDetail.ScrollToBeginning()
+
+	node, ok := v.SelectedResource()
+	if !ok {
+		fmt.Fprintf(v.ResourceDetail, "[%s]No resource selected", v.Config.Text)
+		return
+	}
+
+	v.ResourceDetail.SetText(v.describe(node, v.resources))
+}
+
+// SelectedResource returns the resource of the selected row of the main
+// table.
+func (v *AppView) SelectedResource() (argocd.ApplicationNode, bool) {
+	row, _ := v.MainTable.GetSelection()
+	if row < 1 || row > len(v.shownResources) {
+		return argocd.ApplicationNode{}, false
+	}
+
+	return v.shownResources[row-1], true
+}
+
+// describe formats every field of node, with its owners looked up in tree.
+func (v *AppView) describe(node argocd.ApplicationNode, tree []argocd.ApplicationNode) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "[%s::b]%s/%s[-::B]\n", v.Config.Header, tview.Escape(node.Kind), tview.Escape(node.Name))
+
+	field := func(name string, value string) {
+		if value != "" {
+			fmt.Fprintf(b, "[%s]%s:[-] %s\n", v.Config.Header, name, tview.Escape(value))
+		}
+	}
+	section := func(name string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(b, "\n[%s]%s[-]\n", v.Config.Header, name)
+		for _, value := range values {
+			fmt.Fprintf(b, "  %s\n", tview.Escape(value))
+		}
+	}
+
+	apiVersion := node.Version
+	if node.Group != "" {
+		apiVersion = node.Group + "/" + node.Version
+	}
+
+	field("Namespace", node.Namespace)
+	field("API version", apiVersion)
+	fmt.Fprintf(b, "[%s]Health:[-] [%s]%s[-]\n", v.Config.Header, v.stateColor(node.Health.Status), tview.Escape(or(node.Health.Status, "-")))
+	field("Health message", node.Health.Message)
+	if !node.CreatedAt.IsZero() {
+		field("Age", fmt.Sprintf("%s (created %s)", utils.ShortDuration(time.Since(node.CreatedAt)), node.CreatedAt.Local().Format(time.DateTime)))
+	}
+	field("Resource version", node.ResourceVersion)
+	field("UID", node.UID)
+
+	owners := []string{}
+	for i, owner := range argocd.Owners(node, tree) {
+		owners = append(owners, strings.Repeat("  ", i)+owner.Kind+"/"+owner.Name)
+	}
+	if len(owners) > 0 {
+		owners = append(owners, strings.Repeat("  ", len(owners))+node.Kind+"/"+node.Name)
+	}
+	section("Owners", owners)
+
+	if len(node.ParentRefs) > 1 {
+		others := []string{}
+		for _, parent := range node.ParentRefs[1:] {
+			others = append(others, parent.Kind+"/"+parent.Name)
+		}
+		section("Other parents", others)
+	}
+
+	section("Images", node.Images)
+	section("Info", search.Info(node))
+	section("Labels", keyValues(node.NetworkingInfo.Labels))
+	section("Target labels", keyValues(node.NetworkingInfo.TargetLabels))
+	section("External URLs", node.NetworkingInfo.ExternalURLs)
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// stateColor is the foreground of state in the table, for text.
+func (v *AppView) stateColor(state string) tcell.Color {
+	fg, _, _ := v.Config.Style(state).Decompose()
+	if fg == tcell.ColorDefault {
+		return v.Config.Text
+	}
+	return fg
+}
+
+func keyValues(m map[string]string) []string {
+	lines := make([]string, 0, len(m))
+	for key, value := range m {
+		lines = append(lines, key+"="+value)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func or(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
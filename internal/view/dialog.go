@@ -0,0 +1,200 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/main/services/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const dialogPage = "dialog"
+
+// SetBanner shows a persistent one line banner above the main page. An empty
+// text removes it.
+func (v *AppView) SetBanner(text string, color tcell.Color) {
+	v.Layout.RemoveItem(v.Banner)
+
+	if text == "" {
+		return
+	}
+
+	v.Banner.
+		SetText(text).
+		SetTextColor(utils.GetContrastColor(color)).
+		SetBackgroundColor(color)
+
+	v.Layout.Clear()
+	v.Layout.
+		AddItem(v.Banner, 1, 0, false).
+		AddItem(v.MainPageContainer, 0, 1, true).
+		AddItem(v.StatusBar, 1, 0, false)
+}
+
+func (v *AppView) HasDialog() bool {
+	return v.Pages.HasPage(dialogPage)
+}
+
+func (v *AppView) closeDialog(prev tview.Primitive) {
+	v.Pages.RemovePage(dialogPage)
+	v.App.SetFocus(prev)
+}
+
+// ShowMessage shows text in a modal until it is dismissed.
+func (v *AppView) ShowMessage(text string) {
+	prev := v.App.GetFocus()
+	if v.HasDialog() {
+		v.Pages.RemovePage(dialogPage)
+	}
+
+	message := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"OK"}).
+		SetBackgroundColor(v.Config.Background).
+		SetTextColor(v.Config.Text).
+		SetButtonBackgroundColor(v.Config.Selected).
+		SetButtonTextColor(utils.GetContrastColor(v.Config.Selected)).
+		SetDoneFunc(func(int, string) {
+			v.closeDialog(prev)
+		})
+
+	message.
+		SetBorderColor(v.Config.Selected)
+
+	v.Pages.AddPage(dialogPage, message, true, true)
+	v.App.SetFocus(message)
+}
+
+// Confirm asks the user to type expected before onConfirm runs, which guards
+// changes to protected servers.
+func (v *AppView) Confirm(prompt string, expected string, onConfirm func()) {
+	prev := v.App.GetFocus()
+
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(prompt)
+
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetFieldBackgroundColor(v.Config.Background)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			if input.GetText() != expected {
+				text.SetText(fmt.Sprintf("%s\n\n[%s]%s does not match", prompt, v.Config.Degraded, tview.Escape(fmt.Sprintf("%q", input.GetText()))))
+				return
+			}
+
+			v.closeDialog(prev)
+			onConfirm()
+		case tcell.KeyEsc:
+			v.closeDialog(prev)
+		}
+	})
+
+	form := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, false).
+		AddItem(input, 1, 0, true)
+
+	form.
+		SetBorder(true).
+		SetBorderColor(v.Config.Degraded).
+		SetTitle(" Confirm ")
+
+	v.Pages.AddPage(dialogPage, modal(form, 50, 25), true, true)
+	v.App.SetFocus(input)
+}
+
+// ShowProblems lists lines in a scrollable modal until Esc or Enter.
+func (v *AppView) ShowProblems(title string, lines []string) {
+	prev := v.App.GetFocus()
+
+	text := tview.NewTextView().
+		SetText(strings.Join(lines, "\n")).
+		SetTextColor(v.Config.Text).
+		SetScrollable(true).
+		SetWrap(true)
+
+	text.
+		SetBorder(true).
+		SetBorderColor(v.Config.Degraded).
+		SetTitle(title)
+
+	text.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape || key == tcell.KeyEnter {
+			v.closeDialog(prev)
+		}
+	})
+
+	v.Pages.AddPage(dialogPage, modal(text, 70, 50), true, true)
+	v.App.SetFocus(text)
+}
+
+// Pick lets the user choose one of items, starting at current. onChange runs
+// whenever the highlighted item changes, its error is shown below the list.
+// onDone gets the chosen item, or ok false when the picker was cancelled.
+func (v *AppView) Pick(title string, items []string, current string, onChange func(item string) error, onDone func(item string, ok bool)) {
+	prev := v.App.GetFocus()
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+
+	status := tview.NewTextView().
+		SetDynamicColors(true)
+
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, true).
+		AddItem(status, 3, 0, false)
+
+	frame.
+		SetBorder(true).
+		SetTitle(title)
+
+	restyle := func() {
+		list.
+			SetMainTextColor(v.Config.Text).
+			SetSelectedTextColor(utils.GetContrastColor(v.Config.Selected)).
+			SetSelectedBackgroundColor(v.Config.Selected).
+			SetBackgroundColor(v.Config.Background)
+		status.SetBackgroundColor(v.Config.Background)
+		frame.
+			SetBorderColor(v.Config.Selected).
+			SetTitleColor(v.Config.Text).
+			SetBackgroundColor(v.Config.Background)
+	}
+
+	for i, item := range items {
+		list.AddItem(item, "", 0, nil)
+		if item == current {
+			list.SetCurrentItem(i)
+		}
+	}
+
+	restyle()
+
+	list.SetChangedFunc(func(index int, item string, _ string, _ rune) {
+		status.Clear()
+		if err := onChange(item); err != nil {
+			fmt.Fprintf(status, "[%s]%s", v.Config.Degraded, tview.Escape(err.Error()))
+		}
+		restyle()
+	})
+
+	list.SetSelectedFunc(func(index int, item string, _ string, _ rune) {
+		v.closeDialog(prev)
+		onDone(item, true)
+	})
+
+	list.SetDoneFunc(func() {
+		v.closeDialog(prev)
+		onDone("", false)
+	})
+
+	v.Pages.AddPage(dialogPage, modal(frame, 40, 60), true, true)
+	v.App.SetFocus(list)
+}
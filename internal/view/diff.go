@@ -0,0 +1,84 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/main/services/diff"
+	"github.com/rivo/tview"
+)
+
+// ShowDiff shows the changes of an edit with a problem above them, and hint
+// telling the keys to apply, edit again or discard them. The keys are
+// handled by the commands of the Review context.
+func (v *AppView) ShowDiff(title string, lines []diff.Line, problem string, hint string) {
+	prev := v.App.GetFocus()
+	if v.DiffOpen() {
+		prev = v.diffPrev
+	}
+	if v.HasDialog() {
+		v.Pages.RemovePage(dialogPage)
+	}
+
+	b := &strings.Builder{}
+	if problem != "" {
+		fmt.Fprintf(b, "[%s]%s[-]\n\n", v.Config.Degraded, tview.Escape(problem))
+	}
+	for _, line := range diff.Context(lines, 3) {
+		text := tview.Escape(line.Text)
+		switch line.Op {
+		case diff.Insert:
+			fmt.Fprintf(b, "[%s]+ %s[-]\n", v.Config.Healthy, text)
+		case diff.Delete:
+			fmt.Fprintf(b, "[%s]- %s[-]\n", v.Config.Degraded, text)
+		case diff.Skip:
+			fmt.Fprintf(b, "[%s]  ⋯ %s[-]\n", v.Config.Border, text)
+		default:
+			fmt.Fprintf(b, "  %s\n", text)
+		}
+	}
+
+	changes := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetTextColor(v.Config.Text).
+		SetText(b.String())
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextColor(v.Config.Text).
+		SetText(hint)
+
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(changes, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+
+	color := v.Config.Selected
+	if problem != "" {
+		color = v.Config.Degraded
+	}
+	frame.
+		SetBorder(true).
+		SetBorderColor(color).
+		SetTitle(title)
+
+	v.diff = changes
+	v.diffPrev = prev
+	v.Pages.AddPage(dialogPage, modal(frame, 80, 80), true, true)
+	v.App.SetFocus(changes)
+}
+
+// DiffOpen reports whether the changes of an edit are shown.
+func (v *AppView) DiffOpen() bool {
+	return v.diff != nil && v.diff.HasFocus()
+}
+
+// HideDiff closes the changes and focuses what was focused before.
+func (v *AppView) HideDiff() {
+	if v.DiffOpen() {
+		v.closeDialog(v.diffPrev)
+	}
+	v.diff = nil
+}
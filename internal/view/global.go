@@ -0,0 +1,124 @@
+package view
+
+import (
+	"fmt"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/columns"
+	"example.com/main/services/config"
+	"example.com/main/services/search"
+	"github.com/rivo/tview"
+)
+
+const globalSearchPage = "global search"
+
+func newGlobalSearch(config *config.Config) (*tview.Flex, *tview.InputField, *tview.Table, *tview.TextView) {
+	input := tview.NewInputField().
+		SetLabel("/ ").
+		SetFieldBackgroundColor(config.Background)
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(table, 0, 1, false).
+		AddItem(status, 1, 0, false)
+
+	frame.
+		SetBorder(true).
+		SetBorderColor(config.Selected).
+		SetTitle(" Search all applications ")
+
+	return frame, input, table, status
+}
+
+// ShowGlobalSearch opens the global search page with an empty query.
+func (v *AppView) ShowGlobalSearch() {
+	v.GlobalInput.SetText("")
+	v.GlobalTable.Clear()
+	v.GlobalStatus.Clear()
+	v.Pages.ShowPage(globalSearchPage)
+	v.App.SetFocus(v.GlobalInput)
+}
+
+func (v *AppView) HideGlobalSearch() {
+	v.Pages.HidePage(globalSearchPage)
+	v.App.SetFocus(v.AppTable)
+}
+
+func (v *AppView) GlobalSearchOpen() bool {
+	page, _ := v.Pages.GetFrontPage()
+	return page == globalSearchPage
+}
+
+// UpdateGlobalResults lists the resources found by the global search, with
+// the matches of filter highlighted.
+func (v *AppView) UpdateGlobalResults(results []model.GlobalResult, filter search.Query) {
+	v.GlobalTable.Clear()
+	v.setColumnHeaders(v.GlobalTable, []string{"Application"}, v.Config.Columns["search"], columns.Sort{})
+
+	for row, result := range results {
+		app := highlight(result.App, filter.Matches(result.App, "app"))
+		v.GlobalTable.SetCell(row+1, 0, v.stateCell(app, result.Node.Health.Status))
+
+		resource := columns.Resource{Node: result.Node, Sync: result.Sync}
+		v.setResourceCells(v.GlobalTable, row+1, 1, v.Config.Columns["search"], resource, filter, true)
+	}
+
+	v.GlobalTable.Select(1, 0).ScrollToBeginning()
+}
+
+// SetGlobalStatus shows how far the global search got through the
+// applications, and how many resources it found.
+func (v *AppView) SetGlobalStatus(searched int, total int, failed int, found int) {
+	v.GlobalInput.SetFieldTextColor(v.Config.Text)
+	v.GlobalStatus.SetText(v.scanStatus(searched, total, failed))
+
+	if found >= 0 {
+		fmt.Fprintf(v.GlobalStatus, " │ %d resources found", found)
+	}
+}
+
+// scanStatus tells how many of the trees of total applications were fetched
+// by a page that looks across applications.
+func (v *AppView) scanStatus(searched int, total int, failed int) string {
+	color := v.Config.Progressing
+	if searched == total {
+		color = v.Config.Text
+	}
+	status := fmt.Sprintf("[%s]%d of %d applications searched", color, searched, total)
+
+	if failed > 0 {
+		status += fmt.Sprintf(", [%s]%d failed[%s]", v.Config.Degraded, failed, color)
+	}
+	return status
+}
+
+// ShowGlobalError explains why query is invalid in place of the status.
+func (v *AppView) ShowGlobalError(query string, err error) {
+	v.GlobalInput.SetFieldTextColor(v.Config.Degraded)
+	v.GlobalStatus.SetText(v.queryError(query, err))
+}
+
+// MoveGlobalSelection moves the selected result by dir rows.
+func (v *AppView) MoveGlobalSelection(dir int) {
+	row, _ := v.GlobalTable.GetSelection()
+	row = max(1, min(row+dir, v.GlobalTable.GetRowCount()-1))
+	v.GlobalTable.Select(row, 0)
+}
+
+// GlobalSelection returns the index of the selected result, or -1.
+func (v *AppView) GlobalSelection() int {
+	row, _ := v.GlobalTable.GetSelection()
+	if row < 1 || row >= v.GlobalTable.GetRowCount() {
+		return -1
+	}
+	return row - 1
+}
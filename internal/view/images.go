@@ -0,0 +1,113 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"example.com/main/services/config"
+	"example.com/main/services/images"
+	"github.com/rivo/tview"
+)
+
+const imagesPage = "images"
+
+func newImages(config *config.Config) (*tview.Flex, *tview.Table, *tview.TextView) {
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+
+	frame := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(status, 1, 0, false)
+
+	frame.
+		SetBorder(true).
+		SetBorderColor(config.Selected).
+		SetTitle(" Images ")
+
+	return frame, table, status
+}
+
+// ShowImages opens the images page.
+func (v *AppView) ShowImages() {
+	v.ImagesTable.Clear()
+	v.ImagesStatus.Clear()
+	v.Pages.ShowPage(imagesPage)
+	v.App.SetFocus(v.ImagesTable)
+}
+
+func (v *AppView) HideImages() {
+	v.Pages.HidePage(imagesPage)
+	v.App.SetFocus(v.AppTable)
+}
+
+func (v *AppView) ImagesOpen() bool {
+	page, _ := v.Pages.GetFrontPage()
+	return page == imagesPage
+}
+
+// UpdateImages lists the images of the report, with the versions of images
+// that run in more than one version marked. The selected row is kept.
+func (v *AppView) UpdateImages(report []images.Usage) {
+	row, _ := v.ImagesTable.GetSelection()
+	v.ImagesTable.Clear()
+
+	for i, column := range []string{"Image", "Version", "Apps", "Resources", "Drift", "Applications"} {
+		v.ImagesTable.SetCell(0, i,
+			tview.NewTableCell(column).
+				SetTextColor(v.Config.Header).
+				SetSelectable(false))
+	}
+
+	for i, usage := range report {
+		color := v.Config.Text
+		drift := ""
+		if usage.Drift {
+			color = v.Config.Progressing
+			drift = "drift"
+		}
+
+		values := []string{
+			usage.Image,
+			usage.Version,
+			strconv.Itoa(len(usage.Applications)),
+			strconv.Itoa(usage.Resources),
+			drift,
+			strings.Join(usage.Applications, ", "),
+		}
+		for col, value := range values {
+			cell := tview.NewTableCell(tview.Escape(value)).SetTextColor(color)
+			if col == 2 || col == 3 {
+				cell.SetAlign(tview.AlignRight)
+			}
+			if col == len(values)-1 {
+				cell.SetExpansion(1)
+			}
+			v.ImagesTable.SetCell(i+1, col, cell)
+		}
+	}
+
+	v.ImagesTable.Select(max(1, min(row, len(report))), 0)
+}
+
+// SetImagesStatus shows how far the scan got through the applications, and
+// how many images it found.
+func (v *AppView) SetImagesStatus(searched int, total int, failed int, report []images.Usage) {
+	v.ImagesStatus.SetText(v.scanStatus(searched, total, failed))
+
+	count := map[string]bool{}
+	for _, usage := range report {
+		count[usage.Image] = true
+	}
+	fmt.Fprintf(v.ImagesStatus, " │ %d images", len(count))
+
+	if drifting := images.Drifting(report); drifting > 0 {
+		fmt.Fprintf(v.ImagesStatus, ", [%s]%d in more than one version", v.Config.Progressing, drifting)
+	}
+}
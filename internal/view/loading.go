@@ -0,0 +1,31 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// ShowPlaceholder replaces the contents of table with a single line, for
+// loading indicators and errors.
+func (v *AppView) ShowPlaceholder(table *tview.Table, text string, color tcell.Color) {
+	table.Clear()
+	table.SetCell(0, 0,
+		tview.NewTableCell(text).
+			SetTextColor(color).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false))
+
+	if table == v.MainTable {
+		v.shownResources = nil
+		v.updateDetail()
+	}
+}
+
+// ShowLoading shows text with frame of the spinner animation.
+func (v *AppView) ShowLoading(table *tview.Table, text string, frame int) {
+	v.ShowPlaceholder(table, fmt.Sprintf("%s %s…", spinnerFrames[frame%len(spinnerFrames)], text), v.Config.Progressing)
+}
@@ -0,0 +1,136 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"example.com/main/services/search"
+	"github.com/rivo/tview"
+)
+
+// ShowSearchError explains why query is invalid next to the search input,
+// underlining the offending token. A nil err clears it.
+func (v *AppView) ShowSearchError(query string, err error) {
+	if v.SearchInput == nil {
+		return
+	}
+
+	v.searchError.Clear()
+
+	if err == nil {
+		v.SearchInput.SetFieldTextColor(v.Config.Text)
+		v.CommandBar.ResizeItem(v.searchError, 0, 0)
+		return
+	}
+
+	v.SearchInput.SetFieldTextColor(v.Config.Degraded)
+	v.CommandBar.ResizeItem(v.searchError, 0, 1)
+	v.searchError.SetText(v.queryError(query, err))
+}
+
+// queryError formats err for query with the offending token underlined.
+func (v *AppView) queryError(query string, err error) string {
+	message := err.Error()
+	text := ""
+
+	var queryErr *search.Error
+	if errors.As(err, &queryErr) {
+		message = queryErr.Message
+		text = fmt.Sprintf("%s[%s::u]%s[-::-]%s  ",
+			tview.Escape(query[:queryErr.Start]),
+			v.Config.Degraded,
+			tview.Escape(query[queryErr.Start:queryErr.End]),
+			tview.Escape(query[queryErr.End:]))
+	}
+
+	return text + fmt.Sprintf("[%s]%s", v.Config.Degraded, tview.Escape(message))
+}
+
+// highlight escapes text for a tview cell and underlines the runes at
+// positions.
+func highlight(text string, positions []int) string {
+	if len(positions) == 0 {
+		return tview.Escape(text)
+	}
+
+	marked := map[int]bool{}
+	for _, position := range positions {
+		marked[position] = true
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && marked[end] == marked[start] {
+			end++
+		}
+
+		chunk := tview.Escape(string(runes[start:end]))
+		if marked[start] {
+			fmt.Fprintf(&b, "[::u]%s[::U]", chunk)
+		} else {
+			b.WriteString(chunk)
+		}
+		start = end
+	}
+
+	return b.String()
+}
+
+// SetHighlightMode switches between hiding the rows a search does not match
+// and only highlighting the matches. The tables have to be rendered again.
+func (v *AppView) SetHighlightMode(on bool) {
+	v.highlightMatches = on
+	v.updateTitles()
+}
+
+// setMatches remembers the rows of table that matched the search, for
+// NextMatch.
+func (v *AppView) setMatches(table *tview.Table, rows []int) {
+	v.matchRows[table] = rows
+}
+
+// NextMatch moves the focused table to the next row matched by the search,
+// or to the previous one when dir is -1, wrapping around at the ends. It
+// returns false when no row matches.
+func (v *AppView) NextMatch(dir int) bool {
+	table, ok := v.App.GetFocus().(*tview.Table)
+	if !ok || len(v.matchRows[table]) == 0 {
+		return false
+	}
+
+	rows := v.matchRows[table]
+
+	selectable, _ := table.GetSelectable()
+	current, _ := table.GetSelection()
+	if !selectable {
+		current, _ = table.GetOffset()
+	}
+
+	next := rows[0]
+	if dir < 0 {
+		next = rows[len(rows)-1]
+	}
+
+	for i := range rows {
+		row := rows[i]
+		if dir < 0 {
+			row = rows[len(rows)-1-i]
+		}
+		if (dir > 0 && row > current) || (dir < 0 && row < current) {
+			next = row
+			break
+		}
+	}
+
+	if !selectable {
+		table.SetOffset(next, 0)
+		return true
+	}
+
+	table.Select(next, 0)
+	return true
+}
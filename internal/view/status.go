@@ -0,0 +1,84 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/main/internal/model"
+	"example.com/main/services/argocd"
+	"example.com/main/services/utils"
+	"github.com/rivo/tview"
+)
+
+var statusHealthOrder = []argocd.ApplicationHealthStatus{
+	argocd.StatusHealthy,
+	argocd.StatusProgressing,
+	argocd.StatusDegraded,
+	argocd.StatusMissing,
+	argocd.StatusSuspended,
+	argocd.StatusUnknown,
+}
+
+// UpdateStatus renders the status bar: where we are connected, as whom, the
+// state of the watch stream, the application counts by health and the last
+// transient message.
+func (v *AppView) UpdateStatus(status model.Status, apps []argocd.ApplicationItem, now time.Time) {
+	muted := fmt.Sprintf("[%s:-:-]", v.Config.Header)
+	text := fmt.Sprintf("[%s:-:-]", v.Config.Text)
+	separator := muted + " │ " + text
+
+	parts := []string{}
+
+	server := tview.Escape(status.Server)
+	if status.Context != "" && status.Context != status.Server {
+		server = fmt.Sprintf("%s %s(%s)%s", tview.Escape(status.Context), muted, server, text)
+	}
+	parts = append(parts, server)
+
+	if status.User != "" {
+		parts = append(parts, muted+"user "+text+tview.Escape(status.User))
+	}
+
+	if status.Version != "" {
+		parts = append(parts, muted+"argocd "+text+tview.Escape(status.Version))
+	}
+
+	watch := string(status.Watch)
+	switch status.Watch {
+	case model.WatchLive:
+		watch = v.Config.StyleTag(string(argocd.StatusHealthy)) + watch + "[-:-:-]" + text
+	case model.WatchReconnecting:
+		watch = v.Config.StyleTag(string(argocd.StatusDegraded)) + watch + "[-:-:-]" + text
+	}
+	parts = append(parts, muted+"watch "+text+watch)
+
+	if !status.LastUpdate.IsZero() {
+		parts = append(parts, muted+"updated "+text+utils.ShortDuration(now.Sub(status.LastUpdate))+" ago")
+	}
+
+	counts := map[argocd.ApplicationHealthStatus]int{}
+	for _, app := range apps {
+		counts[app.Status.Health.Status]++
+	}
+
+	health := []string{}
+	for _, state := range statusHealthOrder {
+		if counts[state] > 0 {
+			health = append(health, fmt.Sprintf("%s%d %s[-:-:-]%s", v.Config.StyleTag(string(state)), counts[state], state, text))
+		}
+	}
+	if len(health) > 0 {
+		parts = append(parts, strings.Join(health, " "))
+	}
+
+	if status.Message != "" {
+		message := tview.Escape(status.Message)
+		if status.MessageIsError {
+			message = v.Config.StyleTag(string(argocd.OperationError)) + message + "[-:-:-]"
+		}
+		parts = append(parts, message)
+	}
+
+	v.StatusBar.SetText(" " + text + strings.Join(parts, separator))
+}
@@ -2,11 +2,15 @@ package view
 
 import (
 	"fmt"
-	"strings"
+	"io"
+	"os"
+	"slices"
 
 	"example.com/main/internal/model"
 	"example.com/main/services/argocd"
+	"example.com/main/services/columns"
 	"example.com/main/services/config"
+	"example.com/main/services/search"
 	"example.com/main/services/utils"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -21,27 +25,45 @@ type AppView struct {
 	SideBar              *tview.Flex
 	AppTable             *tview.Table
 	HelpModal            tview.Primitive
-	HelpPage             *tview.List
+	HelpPage             *tview.Table
 	CommandBar           *tview.Flex
 	SearchInput          *tview.InputField
 	MainContentContainer *tview.Flex
 	MainPageContainer    *tview.Flex
+	Layout               *tview.Flex
+	Banner               *tview.TextView
 	MainTable            *tview.Table
-	StatusBox            *tview.Box
+	ResourceDetail       *tview.TextView
+	StatusBar            *tview.TextView
+	GlobalSearch         *tview.Flex
+	GlobalInput          *tview.InputField
+	GlobalTable          *tview.Table
+	GlobalStatus         *tview.TextView
+	Images               *tview.Flex
+	ImagesTable          *tview.Table
+	ImagesStatus         *tview.TextView
 	Logger               *logrus.Logger
+	Terminal             io.Writer
+	searchError          *tview.TextView
+	query                search.Query
+	highlightMatches     bool
+	matchRows            map[*tview.Table][]int
+	diff                 *tview.TextView
+	diffPrev             tview.Primitive
+	// ResourceSort orders the main table, ResourceSync has the sync states of
+	// the resources of the selected application
+	ResourceSort columns.Sort
+	ResourceSync map[string]argocd.ApplicationSyncStatus
+	// the tree of the selected application and the rows of the main table
+	resources      []argocd.ApplicationNode
+	shownResources []argocd.ApplicationNode
+	appsCount      string
+	appsNote       string
+	resourcesNote  string
 }
 
 func NewAppView(app *tview.Application, config *config.Config, logger *logrus.Logger) *AppView {
-	theme := tview.Theme{
-		PrimitiveBackgroundColor: config.Background,
-		BorderColor:              config.Border,
-		TitleColor:               config.Text,
-		PrimaryTextColor:         config.Text,
-		SecondaryTextColor:       config.Progressing,
-		InverseTextColor:         config.Background,
-	}
-
-	tview.Styles = theme
+	setStyles(config)
 
 	tableStyle := tcell.StyleDefault.
 		Bold(true)
@@ -60,17 +82,9 @@ func NewAppView(app *tview.Application, config *config.Config, logger *logrus.Lo
 		SetBorder(true).
 		SetTitle(" Main Content ")
 
-	bsBox := tview.NewBox().
-		SetBorder(true).
-		SetTitle(" FooBarBaz ")
-
-	bsBox.
-		SetFocusFunc(func() {
-			bsBox.SetBorderColor(config.Selected)
-		}).
-		SetBlurFunc(func() {
-			bsBox.SetBorderColor(config.Border)
-		})
+	statusBar := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
 
 	appTable.
 		SetTitle(" Applications ").
@@ -116,25 +130,12 @@ func NewAppView(app *tview.Application, config *config.Config, logger *logrus.Lo
 	mainPageContainer.
 		AddItem(mainPage, 0, 1, true)
 
-	modal := func(p tview.Primitive, width, height int) tview.Primitive {
-		return tview.NewFlex().
-			AddItem(nil, 0, 1, false).
-			AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
-				AddItem(nil, 0, 1, false).
-				AddItem(p, height, 1, true).
-				AddItem(nil, 0, 1, false), width, 1, true).
-			AddItem(nil, 0, 1, false)
-	}
-
-	helpPage := tview.NewList().
-		SetHighlightFullLine(true).
-		ShowSecondaryText(false).
-		SetSelectedBackgroundColor(config.Selected).
-		SetSelectedTextColor(config.Background)
+	helpPage := tview.NewTable().
+		SetSelectable(false, false)
 
 	helpPage.
 		SetBorder(true).
-		SetTitle("Help Page")
+		SetTitle(" Help Page ")
 
 	helpPage.
 		SetBlurFunc(func() {
@@ -144,11 +145,25 @@ func NewAppView(app *tview.Application, config *config.Config, logger *logrus.Lo
 			helpPage.SetBorderColor(config.Selected)
 		})
 
-	helpModal := modal(helpPage, 80, 40)
+	helpModal := modal(helpPage, 80, 80)
+
+	banner := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(mainPageContainer, 0, 1, true).
+		AddItem(statusBar, 1, 0, false)
+
+	globalSearch, globalInput, globalTable, globalStatus := newGlobalSearch(config)
+	imagesFrame, imagesTable, imagesStatus := newImages(config)
 
 	pages := tview.NewPages().
-		AddPage("main page", mainPageContainer, true, true).
-		AddPage("help page", helpModal, true, false)
+		AddPage("main page", layout, true, true).
+		AddPage("help page", helpModal, true, false).
+		AddPage(globalSearchPage, modal(globalSearch, 90, 80), true, false).
+		AddPage(imagesPage, modal(imagesFrame, 90, 80), true, false)
 
 	appView := &AppView{
 		App:                  app,
@@ -160,27 +175,136 @@ func NewAppView(app *tview.Application, config *config.Config, logger *logrus.Lo
 		AppTable:             appTable,
 		MainContentContainer: mainContentContainer,
 		MainPageContainer:    mainPageContainer,
+		Layout:               layout,
+		Banner:               banner,
 		CommandBar:           commandBar,
 		MainTable:            mainTable,
-		StatusBox:            bsBox,
+		ResourceDetail:       newResourceDetail(config),
+		StatusBar:            statusBar,
+		GlobalSearch:         globalSearch,
+		GlobalInput:          globalInput,
+		GlobalTable:          globalTable,
+		GlobalStatus:         globalStatus,
+		Images:               imagesFrame,
+		ImagesTable:          imagesTable,
+		ImagesStatus:         imagesStatus,
 		Config:               config,
 		Logger:               logger,
+		Terminal:             os.Stdout,
+		matchRows:            map[*tview.Table][]int{},
 	}
 
 	// appView.AddSearchInput()
 
+	mainTable.SetSelectionChangedFunc(func(row, column int) {
+		appView.updateDetail()
+	})
+	mainContentContainer.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		appView.layoutDetail(width - 2)
+		return x + 1, y + 1, width - 2, height - 2
+	})
+
 	return appView
 }
 
+func setStyles(config *config.Config) {
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor: config.Background,
+		BorderColor:              config.Border,
+		TitleColor:               config.Text,
+		PrimaryTextColor:         config.Text,
+		SecondaryTextColor:       config.Progressing,
+		InverseTextColor:         config.Background,
+	}
+}
+
+// ApplyConfig switches to a reloaded config. The config is copied into the
+// current one, because the focus handlers read the colors from it, and the
+// colors the widgets took from tview.Styles when they were created are set
+// again. Table contents have to be rendered again by the caller.
+func (v *AppView) ApplyConfig(cfg *config.Config) {
+	*v.Config = *cfg
+	setStyles(v.Config)
+
+	boxes := []*tview.Box{
+		v.Pages.Box,
+		v.Layout.Box,
+		v.MainPageContainer.Box,
+		v.MainPage.Box,
+		v.SideBar.Box,
+		v.AppTable.Box,
+		v.MainContentContainer.Box,
+		v.MainTable.Box,
+		v.ResourceDetail.Box,
+		v.HelpPage.Box,
+		v.CommandBar.Box,
+		v.StatusBar.Box,
+		v.GlobalSearch.Box,
+		v.GlobalInput.Box,
+		v.GlobalTable.Box,
+		v.GlobalStatus.Box,
+		v.Images.Box,
+		v.ImagesTable.Box,
+		v.ImagesStatus.Box,
+	}
+
+	for _, box := range boxes {
+		box.SetBackgroundColor(v.Config.Background)
+		box.SetTitleColor(v.Config.Text)
+		box.SetBorderColor(v.Config.Border)
+	}
+
+	// the focused pane keeps its highlighted border
+	switch {
+	case v.AppTable.HasFocus():
+		v.AppTable.SetBorderColor(v.Config.Selected)
+	case v.MainContentContainer.HasFocus():
+		v.MainContentContainer.SetBorderColor(v.Config.Selected)
+	case v.HelpPage.HasFocus():
+		v.HelpPage.SetBorderColor(v.Config.Selected)
+	}
+
+	v.GlobalSearch.SetBorderColor(v.Config.Selected)
+	v.Images.SetBorderColor(v.Config.Selected)
+	v.GlobalInput.
+		SetFieldBackgroundColor(v.Config.Background).
+		SetFieldTextColor(v.Config.Text).
+		SetLabelColor(v.Config.Text)
+
+	if v.SearchInput != nil {
+		v.SearchInput.
+			SetFieldBackgroundColor(v.Config.Background).
+			SetFieldTextColor(v.Config.Text).
+			SetBackgroundColor(v.Config.Background)
+	}
+}
+
+// modal centers p over the page, sized as a percentage of the screen.
+func modal(p tview.Primitive, widthPercent, heightPercent int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 100-widthPercent, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 100-heightPercent, false).
+			AddItem(p, 0, 2*heightPercent, true).
+			AddItem(nil, 0, 100-heightPercent, false), 0, 2*widthPercent, true).
+		AddItem(nil, 0, 100-widthPercent, false)
+}
+
 func (v *AppView) AddSearchInput() {
 	searchInput := tview.NewInputField()
 	searchInput.SetFieldBackgroundColor(v.Config.Background).
 		SetBorder(false)
 
+	searchError := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+
 	v.SearchInput = searchInput
+	v.searchError = searchError
 
 	v.CommandBar.
-		AddItem(searchInput, 0, 1, true)
+		AddItem(searchInput, 0, 1, true).
+		AddItem(searchError, 0, 0, false)
 }
 
 func (v *AppView) ToggleHelp() {
@@ -192,19 +316,124 @@ func (v *AppView) ToggleHelp() {
 	v.Pages.ShowPage("help page")
 }
 
-func (v *AppView) UpdateHelp(commands map[model.Context]map[model.KeyStroke]*model.Command, filter string) {
+type helpLine struct {
+	header string
+	cmd    *model.Command
+	match  bool
+}
+
+// UpdateHelp renders the commands grouped by context, starting with the
+// focused context and the contexts it falls back to. On wide terminals the
+// groups flow into two columns.
+func (v *AppView) UpdateHelp(commands *model.CommandModel, focused model.Context, filter search.Query) {
 	v.HelpPage.Clear()
+	v.setMatches(v.HelpPage, nil)
+
+	contexts := model.Stack(focused)
+	for _, ctx := range model.Contexts {
+		if !slices.Contains(contexts, ctx) {
+			contexts = append(contexts, ctx)
+		}
+	}
 
-	for ctx, cmdMap := range commands {
-		for trigger, cmd := range cmdMap {
-			if strings.Contains(
-				strings.ToLower(cmd.String()),
-				strings.ToLower(filter),
-			) {
-				v.HelpPage.AddItem(fmt.Sprintf("%c - %-10s - %-10s", trigger, ctx, cmd), "", 0, nil)
+	lines := []helpLine{}
+	for _, ctx := range contexts {
+		visible := []*model.Command{}
+		for _, cmd := range commands.Sorted(ctx) {
+			if !commands.Hidden(cmd) {
+				visible = append(visible, cmd)
 			}
 		}
+
+		group := []helpLine{}
+		if v.highlightMatches {
+			for _, cmd := range visible {
+				group = append(group, helpLine{cmd: cmd, match: !filter.Empty() && filter.Match(cmd.Subject())})
+			}
+		} else {
+			for _, cmd := range search.Rank(visible, filter, (*model.Command).Subject) {
+				group = append(group, helpLine{cmd: cmd, match: true})
+			}
+		}
+
+		if len(group) == 0 {
+			continue
+		}
+
+		lines = append(lines, helpLine{header: string(ctx)})
+		lines = append(lines, group...)
+	}
+
+	if len(lines) == 0 {
+		v.HelpPage.SetCell(0, 0,
+			tview.NewTableCell("No commands").
+				SetTextColor(v.Config.Text).
+				SetAlign(tview.AlignLeft))
+		return
+	}
+
+	columns := [][]helpLine{lines}
+
+	_, _, width, _ := v.Pages.GetRect()
+	if width*80/100 >= 120 && len(lines) > 1 {
+		mid := (len(lines) + 1) / 2
+		right := lines[mid:]
+		if right[0].header == "" {
+			// repeat the header of the group that was split
+			for i := mid - 1; i >= 0; i-- {
+				if lines[i].header != "" {
+					right = append([]helpLine{{header: lines[i].header + " (cont.)"}}, right...)
+					break
+				}
+			}
+		}
+		columns = [][]helpLine{lines[:mid], right}
 	}
+
+	matches := []int{}
+	for i, column := range columns {
+		col := i * 3
+
+		for row, line := range column {
+			if line.match {
+				matches = append(matches, row)
+			}
+
+			if line.header != "" {
+				v.HelpPage.SetCell(row, col,
+					tview.NewTableCell(line.header).
+						SetTextColor(v.Config.Header).
+						SetAttributes(tcell.AttrBold).
+						SetAlign(tview.AlignLeft))
+				continue
+			}
+
+			keys, description := tview.Escape(line.cmd.Keys.Display()), tview.Escape(line.cmd.Description)
+			if line.match {
+				keys = highlight(line.cmd.Keys.Display(), filter.Matches(line.cmd.Keys.Display(), "key"))
+				description = highlight(line.cmd.Description, filter.Matches(line.cmd.Description, ""))
+			}
+
+			v.HelpPage.SetCell(row, col,
+				tview.NewTableCell(keys).
+					SetTextColor(v.Config.Selected).
+					SetAlign(tview.AlignRight))
+
+			v.HelpPage.SetCell(row, col+1,
+				tview.NewTableCell(description).
+					SetTextColor(v.Config.Text).
+					SetAlign(tview.AlignLeft).
+					SetExpansion(1))
+		}
+
+		if i > 0 {
+			v.HelpPage.SetCell(0, col-1, tview.NewTableCell("   "))
+		}
+	}
+
+	slices.Sort(matches)
+	v.setMatches(v.HelpPage, slices.Compact(matches))
+	v.HelpPage.ScrollToBeginning()
 }
 
 func (v *AppView) RemoveHelp() {
@@ -213,64 +442,99 @@ func (v *AppView) RemoveHelp() {
 	v.App.SetFocus(v.AppTable)
 }
 
-func (v *AppView) UpdateAppTable(apps []argocd.ApplicationItem, filter string) {
+// UpdateAppTable renders the applications matching filter. A limit above 0
+// renders only that many rows, the title then tells how many there are.
+// It returns whether rows were left out.
+func (v *AppView) UpdateAppTable(apps []argocd.ApplicationItem, filter search.Query, limit int) bool {
 	v.AppTable.Clear()
+	v.setMatches(v.AppTable, nil)
+	v.appsCount = ""
+	v.updateTitles()
 
 	if len(apps) == 0 {
 		v.AppTable.SetCell(0, 0,
 			tview.NewTableCell("No data").
 				SetTextColor(v.Config.Text).
 				SetAlign(tview.AlignLeft))
-		return
+		return false
 	}
 
-	filteredApps := []argocd.ApplicationItem{}
-
-	if filter == "" {
+	filteredApps := search.Rank(apps, filter, search.Application)
+	if v.highlightMatches {
 		filteredApps = apps
-	} else {
-		for _, app := range apps {
-			if strings.Contains(
-				strings.ToLower(app.Metadata.Name),
-				strings.ToLower(filter),
-			) {
-				filteredApps = append(filteredApps, app)
-			}
-		}
+	}
 
+	truncated := limit > 0 && len(filteredApps) > limit
+	if truncated {
+		v.appsCount = fmt.Sprintf("%d of %d", limit, len(filteredApps))
+		v.updateTitles()
+		filteredApps = filteredApps[:limit]
 	}
 
+	matches := []int{}
 	for i, app := range filteredApps {
-		color := v.Config.Progressing
-
-		switch app.Status.Health.Status {
-		case argocd.StatusDegraded:
-			color = v.Config.Degraded
-		case argocd.StatusHealthy:
-			color = v.Config.Healthy
-		case argocd.StatusProgressing:
-			color = v.Config.Progressing
-		case argocd.StatusMissing:
-			color = v.Config.Missing
+		name := tview.Escape(app.Metadata.Name)
+		if !v.highlightMatches || filter.Match(search.Application(app)) {
+			name = highlight(app.Metadata.Name, filter.Matches(app.Metadata.Name, "name"))
+			matches = append(matches, i)
 		}
 
-		tableCell := tview.NewTableCell(app.Metadata.Name).
-			SetTextColor(color).
-			SetAlign(tview.AlignLeft).
-			SetExpansion(1)
+		v.AppTable.SetCell(i, 0,
+			v.stateCell(name, string(app.Status.Health.Status)).
+				SetExpansion(1))
 
-		tableCell.
-			SetSelectedStyle(
-				tcell.StyleDefault.
-					Background(color).
-					Foreground(utils.GetContrastColor(color)).
-					Bold(true),
-			)
+		// a running or failed operation matters more than the sync state
+		state := string(app.Status.Sync.Status)
+		if op := app.Status.OperationState; op != nil && op.Phase != argocd.OperationSucceeded {
+			state = string(op.Phase)
+		}
 
-		v.AppTable.SetCell(i, 0, tableCell)
+		v.AppTable.SetCell(i, 1, v.stateCell(state, state))
 	}
 
+	v.setMatches(v.AppTable, matches)
 	v.AppTable.Select(0, 0)
+	return truncated
+}
+
+// stateCell renders text in the style of an ArgoCD health state, sync state
+// or operation phase. When selected, the style's color becomes the
+// background.
+func (v *AppView) stateCell(text string, state string) *tview.TableCell {
+	style := v.Config.Style(state)
+	fg, bg, attrs := style.Decompose()
+
+	if fg == tcell.ColorDefault {
+		fg = v.Config.Selected
+	}
+
+	return tview.NewTableCell(text).
+		SetAlign(tview.AlignLeft).
+		SetStyle(style).
+		SetTransparency(bg == v.Config.Background).
+		SetSelectedStyle(
+			tcell.StyleDefault.
+				Background(fg).
+				Foreground(utils.GetContrastColor(fg)).
+				Attributes(attrs | tcell.AttrBold),
+		)
+}
+
+// AppName returns the name of the application shown in row, without the
+// highlighting of the search.
+func (v *AppView) AppName(row int) string {
+	return utils.StripTags(v.AppTable.GetCell(row, 0).Text)
+}
+
+func (v *AppView) SelectApp(name string) bool {
+	for row := 0; row < v.AppTable.GetRowCount(); row++ {
+		if v.AppName(row) == name {
+			v.AppTable.Select(row, 0)
+			return true
+		}
+	}
+
+	return false
 }
 
 func (v *AppView) RemoveSearchBar() {
@@ -279,8 +543,10 @@ func (v *AppView) RemoveSearchBar() {
 	if v.SearchInput != nil {
 		v.SearchInput.SetText("")
 		v.CommandBar.RemoveItem(v.SearchInput)
+		v.CommandBar.RemoveItem(v.searchError)
 	}
-	v.MainContentContainer.SetTitle(" Main Content ")
+	v.query = search.Query{}
+	v.updateTitles()
 	v.App.SetFocus(v.AppTable)
 }
 
@@ -314,6 +580,12 @@ func (v *AppView) Scroll(dir int) {
 			t.SetCurrentItem(t.GetCurrentItem() + 1)
 		}
 	case *tview.Table:
+		if rows, _ := t.GetSelectable(); !rows {
+			row, col := t.GetOffset()
+			t.SetOffset(max(row-dir, 0), col)
+			return
+		}
+
 		row, _ := t.GetSelection()
 		offset := 1
 		newRow := row + offset*-1*dir
@@ -337,6 +609,14 @@ func (v *AppView) ScrollTo(row int) {
 	case *tview.List:
 		t.SetCurrentItem(row)
 	case *tview.Table:
+		if rows, _ := t.GetSelectable(); !rows {
+			if row < 0 {
+				t.ScrollToEnd()
+				return
+			}
+			t.SetOffset(row, 0)
+			return
+		}
 		if row == 0 && t == v.MainTable {
 			t.Select(1, 0)
 			return
@@ -351,8 +631,13 @@ func (v *AppView) ScrollTo(row int) {
 	}
 }
 
+// SearchBarOpen reports whether the command bar is shown.
+func (v *AppView) SearchBarOpen() bool {
+	return v.MainPageContainer.GetItemCount() > 1
+}
+
 func (v *AppView) ToggleCommandBar() {
-	if v.MainPageContainer.GetItemCount() > 1 {
+	if v.SearchBarOpen() {
 		v.RemoveSearchBar()
 		return
 	}
@@ -363,17 +648,56 @@ func (v *AppView) ClearSearch() {
 	v.RemoveSearchBar()
 }
 
-func (v *AppView) SetSearchTitle(search string) {
-	parts := strings.Split(v.MainContentContainer.GetTitle(), "/")
-	title := strings.Trim(parts[0], " ")
-	if search == "" {
-		v.MainContentContainer.SetTitle(fmt.Sprintf(" %s ", title))
-		return
+// SetSearchTitle shows query and how it matches in the title of the main
+// content.
+func (v *AppView) SetSearchTitle(query search.Query) {
+	v.query = query
+	v.updateTitles()
+}
+
+// SetCacheNote marks the contents of table as shown from the cache in the
+// title of its pane. An empty note removes the mark.
+func (v *AppView) SetCacheNote(table *tview.Table, note string) {
+	switch table {
+	case v.AppTable:
+		v.appsNote = note
+	case v.MainTable:
+		v.resourcesNote = note
+	}
+	v.updateTitles()
+}
+
+func (v *AppView) updateTitles() {
+	note := func(text string) string {
+		if text == "" {
+			return ""
+		}
+		return fmt.Sprintf("[%s]%s[-] ", v.Config.Progressing, tview.Escape(text))
 	}
 
-	searchStr := fmt.Sprintf(" / %s", search)
+	title := " Applications "
+	if v.appsCount != "" {
+		title = fmt.Sprintf(" Applications %s ", v.appsCount)
+	}
+	v.AppTable.SetTitle(title + note(v.appsNote))
 
-	v.MainContentContainer.SetTitle(fmt.Sprintf(" %s%s ", title, searchStr))
+	title = " Main Content "
+	if v.query.Text != "" {
+		mode := "text"
+		if v.query.Mode != search.ModeSubstring {
+			mode = string(v.query.Mode)
+		}
+		if v.query.Sensitive() {
+			mode += ", case-sensitive"
+		} else {
+			mode += ", ignore case"
+		}
+		if v.highlightMatches {
+			mode += ", highlight"
+		}
+		title = fmt.Sprintf(" Main Content / %s (%s) ", tview.Escape(v.query.Text), mode)
+	}
+	v.MainContentContainer.SetTitle(title + note(v.resourcesNote))
 }
 
 // TODO: refactor to global func
@@ -395,10 +719,14 @@ func (v *AppView) SetSearchTitle(search string) {
 // 	v.MainTable.Select(newRow, 0)
 // }
 
-func (v *AppView) UpdateMainContent(resources []argocd.ApplicationNode, filter string) {
+func (v *AppView) UpdateMainContent(resources []argocd.ApplicationNode, filter search.Query) {
 	v.MainTable.Clear()
+	v.setMatches(v.MainTable, nil)
+	v.resources = resources
+	v.shownResources = nil
 
 	if len(resources) == 0 {
+		v.updateDetail()
 		v.MainTable.SetCell(0, 0,
 			tview.NewTableCell("No data").
 				SetTextColor(v.Config.Text).
@@ -406,92 +734,38 @@ func (v *AppView) UpdateMainContent(resources []argocd.ApplicationNode, filter s
 		return
 	}
 
-	columns := []string{
-		"Name",
-		"Kind",
-		"Health",
-		"Namespace",
-		"Version",
-		"Resource Version",
-		"Images",
-	}
+	v.setColumnHeaders(v.MainTable, nil, v.Config.Columns["resources"], v.ResourceSort)
 
-	for i, column := range columns {
-		v.MainTable.SetCell(
-			0,
-			i,
-			tview.NewTableCell(column).
-				SetTextColor(v.Config.Header).
-				SetAlign(tview.AlignLeft),
-		).
-			SetFixed(1, i)
+	rows := make([]columns.Resource, len(resources))
+	for i, node := range resources {
+		rows[i] = columns.Resource{
+			Node: node,
+			Sync: v.ResourceSync[argocd.ResourceKey(node.Group, node.Kind, node.Namespace, node.Name)],
+		}
 	}
+	columns.SortResources(rows, v.ResourceSort)
 
-	filteredResources := []argocd.ApplicationNode{}
+	shown := search.Rank(rows, filter, resourceSubject)
+	if v.highlightMatches {
+		shown = rows
+	}
 
-	if filter == "" {
-		filteredResources = resources
-	} else {
-		for _, manifest := range resources {
-			if strings.Contains(strings.ToLower(manifest.Name), strings.ToLower(filter)) {
-				filteredResources = append(filteredResources, manifest)
-			}
-		}
+	v.shownResources = make([]argocd.ApplicationNode, len(shown))
+	for i, row := range shown {
+		v.shownResources[i] = row.Node
 	}
 
-	for row, manifest := range filteredResources {
-		color := v.Config.Progressing
-
-		switch manifest.Health.Status {
-		case string(argocd.StatusDegraded):
-			color = v.Config.Degraded
-		case string(argocd.StatusHealthy):
-			color = v.Config.Healthy
-		case string(argocd.StatusProgressing):
-			color = v.Config.Progressing
-		case string(argocd.StatusMissing):
-			color = v.Config.Missing
+	matches := []int{}
+	for row, resource := range shown {
+		matched := !v.highlightMatches || filter.Match(search.Resource(resource.Node))
+		if matched {
+			matches = append(matches, row+1)
 		}
 
-		for i, column := range columns {
-			value := ""
-
-			switch column {
-			case "Name":
-				value = manifest.Name
-			case "Kind":
-				value = manifest.Kind
-			case "Health":
-				value = manifest.Health.Status
-			case "Namespace":
-				value = manifest.Namespace
-			case "Version":
-				value = manifest.Version
-			case "Resource Version":
-				value = manifest.ResourceVersion
-			case "Images":
-				value = strings.Join(manifest.Images, ", ")
-			}
-
-			tableCell := tview.NewTableCell(value).
-				SetTextColor(color).
-				SetAlign(tview.AlignLeft)
-
-			tableCell.
-				SetSelectedStyle(
-					tcell.StyleDefault.
-						Background(color).
-						Foreground(utils.GetContrastColor(color)).
-						Bold(true),
-				)
-
-			if i == len(columns)-1 {
-				tableCell.SetExpansion(1)
-			}
-
-			v.MainTable.SetCell(row+1, i, tableCell)
-		}
+		v.setResourceCells(v.MainTable, row+1, 0, v.Config.Columns["resources"], resource, filter, matched)
 	}
 
+	v.setMatches(v.MainTable, matches)
 	v.MainTable.Select(1, 0).ScrollToBeginning()
+	v.updateDetail()
 }
@@ -0,0 +1,110 @@
+package argocd
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheTTL  = 30 * time.Second
+	DefaultCacheSize = 50
+)
+
+// CacheEntry is a cached response. Version is the resourceVersion the
+// response belongs to, it works like an ETag: a newer version of the
+// application makes the entry stale before its TTL ran out.
+type CacheEntry[T any] struct {
+	Key     string
+	Version string
+	Value   T
+	Fetched time.Time
+}
+
+// Cache keeps the most recently used responses, up to Size entries. Stale
+// entries are still returned, so they can be shown while the response is
+// fetched again. It is safe for concurrent use.
+type Cache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// NewCache creates a cache. A size of 0 disables it.
+func NewCache[T any](ttl time.Duration, size int) *Cache[T] {
+	return &Cache[T]{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// SetLimits changes the TTL and size, evicting entries that no longer fit.
+func (c *Cache[T]) SetLimits(ttl time.Duration, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+	c.size = size
+	c.evict()
+}
+
+// SetCacheLimits applies the configured TTL and size to the caches of s.
+func (s *Service) SetCacheLimits(ttl time.Duration, size int) {
+	s.Trees.SetLimits(ttl, size)
+	s.Apps.SetLimits(ttl, min(size, 1))
+}
+
+func (c *Cache[T]) Get(key string) (CacheEntry[T], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return CacheEntry[T]{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(CacheEntry[T]), true
+}
+
+func (c *Cache[T]) Put(key string, version string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := CacheEntry[T]{
+		Key:     key,
+		Version: version,
+		Value:   value,
+		Fetched: time.Now(),
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	c.evict()
+}
+
+// Fresh reports whether entry can be used without asking the server again,
+// because it belongs to version and is younger than the TTL.
+func (c *Cache[T]) Fresh(entry CacheEntry[T], version string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return entry.Version == version && now.Sub(entry.Fetched) < c.ttl
+}
+
+func (c *Cache[T]) evict() {
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(CacheEntry[T]).Key)
+	}
+}
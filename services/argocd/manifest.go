@@ -0,0 +1,233 @@
+package argocd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+type resourceManifestResponse struct {
+	Manifest string `json:"manifest"`
+}
+
+// ResourceManifest fetches the live manifest of node, a resource of
+// application, as JSON.
+func (s *Service) ResourceManifest(ctx context.Context, application string, node ApplicationNode) (string, error) {
+	resp, err := s.do(ctx, s.Client, http.MethodGet, resourceEndpoint(application, node, nil), nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	var result resourceManifestResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return "", fmt.Errorf("error with json decoder: %w", err)
+	}
+
+	return result.Manifest, nil
+}
+
+// PatchResource changes node, a resource of application, with a JSON merge
+// patch.
+func (s *Service) PatchResource(ctx context.Context, application string, node ApplicationNode, patch map[string]any) error {
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("patchType", "application/merge-patch+json")
+
+	// the patch is sent as a JSON string
+	resp, err := s.do(ctx, s.Client, http.MethodPost, resourceEndpoint(application, node, query), string(patchBytes))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func resourceEndpoint(application string, node ApplicationNode, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("namespace", node.Namespace)
+	query.Set("resourceName", node.Name)
+	query.Set("version", node.Version)
+	query.Set("kind", node.Kind)
+	query.Set("group", node.Group)
+
+	return withQuery(fmt.Sprintf("/api/v1/applications/%s/resource", url.PathEscape(application)), query)
+}
+
+type applicationSpecResponse struct {
+	Spec json.RawMessage `json:"spec"`
+}
+
+// ApplicationSpec fetches the spec of application as JSON, with every field
+// the server knows about.
+func (s *Service) ApplicationSpec(ctx context.Context, application string) (json.RawMessage, error) {
+	resp, err := s.do(ctx, s.Client, http.MethodGet, "/api/v1/applications/"+url.PathEscape(application), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var result applicationSpecResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error with json decoder: %w", err)
+	}
+	if len(result.Spec) == 0 || string(result.Spec) == "null" {
+		return nil, errors.New("the application has no spec")
+	}
+
+	return result.Spec, nil
+}
+
+// UpdateApplicationSpec replaces the spec of application.
+func (s *Service) UpdateApplicationSpec(ctx context.Context, application string, spec map[string]any) error {
+	resp, err := s.do(ctx, s.Client, http.MethodPut, fmt.Sprintf("/api/v1/applications/%s/spec", url.PathEscape(application)), spec)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// ToYAML converts a JSON document to block style YAML, keeping the order of
+// its keys.
+func ToYAML(document []byte) (string, error) {
+	var node yaml.Node
+	err := yaml.Unmarshal(document, &node)
+	if err != nil {
+		return "", fmt.Errorf("error converting to YAML: %w", err)
+	}
+	blockStyle(&node)
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&node)
+	if err != nil {
+		return "", fmt.Errorf("error converting to YAML: %w", err)
+	}
+	encoder.Close()
+
+	return b.String(), nil
+}
+
+// blockStyle drops the flow style and quotes JSON comes with, the encoder
+// quotes only the strings that need it.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// FromYAML parses an edited document, which has to be a mapping.
+func FromYAML(text string) (map[string]any, error) {
+	var document map[string]any
+	err := yaml.Unmarshal([]byte(text), &document)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, errors.New("the document is empty")
+	}
+
+	return document, nil
+}
+
+// ValidateSpec checks the fields an application spec cannot do without.
+func ValidateSpec(spec map[string]any) error {
+	if project, _ := spec["project"].(string); project == "" {
+		return errors.New("project is missing")
+	}
+	if _, ok := spec["destination"].(map[string]any); !ok {
+		return errors.New("destination is missing")
+	}
+	if spec["source"] == nil && spec["sources"] == nil {
+		return errors.New("source or sources is missing")
+	}
+
+	return nil
+}
+
+// ValidateManifest checks that manifest still describes node. A resource
+// cannot be renamed or moved by editing it.
+func ValidateManifest(manifest map[string]any, node ApplicationNode) error {
+	metadata, _ := manifest["metadata"].(map[string]any)
+
+	type field struct {
+		name  string
+		value any
+		want  string
+	}
+	fields := []field{
+		{"kind", manifest["kind"], node.Kind},
+		{"metadata.name", metadata["name"], node.Name},
+		{"metadata.namespace", metadata["namespace"], node.Namespace},
+	}
+	if node.Version != "" {
+		apiVersion := node.Version
+		if node.Group != "" {
+			apiVersion = node.Group + "/" + node.Version
+		}
+		fields = append(fields, field{"apiVersion", manifest["apiVersion"], apiVersion})
+	}
+
+	for _, field := range fields {
+		value, _ := field.value.(string)
+		if value != field.want {
+			return fmt.Errorf("%s must stay %q", field.name, field.want)
+		}
+	}
+
+	return nil
+}
+
+// MergePatch returns the JSON merge patch that turns original into edited:
+// changed fields are set, removed ones are null.
+func MergePatch(original map[string]any, edited map[string]any) map[string]any {
+	patch := map[string]any{}
+	for key, before := range original {
+		after, ok := edited[key]
+		if !ok {
+			patch[key] = nil
+			continue
+		}
+
+		beforeMap, beforeIsMap := before.(map[string]any)
+		afterMap, afterIsMap := after.(map[string]any)
+		if beforeIsMap && afterIsMap {
+			if nested := MergePatch(beforeMap, afterMap); len(nested) > 0 {
+				patch[key] = nested
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(before, after) {
+			patch[key] = after
+		}
+	}
+
+	for key, after := range edited {
+		if _, ok := original[key]; !ok {
+			patch[key] = after
+		}
+	}
+
+	return patch
+}
@@ -0,0 +1,158 @@
+package argocd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		edited   string
+		want     map[string]any
+	}{
+		{
+			"unchanged",
+			"a: 1\nb: {c: x}\n",
+			"a: 1\nb: {c: x}\n",
+			map[string]any{},
+		},
+		{
+			"add",
+			"a: 1\n",
+			"a: 1\nb: 2\n",
+			map[string]any{"b": 2},
+		},
+		{
+			"remove",
+			"a: 1\nb: 2\n",
+			"a: 1\n",
+			map[string]any{"b": nil},
+		},
+		{
+			"change",
+			"a: 1\nb: x\n",
+			"a: 2\nb: x\n",
+			map[string]any{"a": 2},
+		},
+		{
+			"nested",
+			"spec: {replicas: 1, selector: {app: web}, paused: true}\n",
+			"spec: {replicas: 3, selector: {app: web}, strategy: Recreate}\n",
+			map[string]any{"spec": map[string]any{"replicas": 3, "paused": nil, "strategy": "Recreate"}},
+		},
+		{
+			"lists are replaced",
+			"args: [a, b]\n",
+			"args: [a, c]\n",
+			map[string]any{"args": []any{"a", "c"}},
+		},
+		{
+			"mapping replaced by scalar",
+			"a: {b: 1}\n",
+			"a: off\n",
+			map[string]any{"a": "off"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := FromYAML(tt.original)
+			if err != nil {
+				t.Fatal(err)
+			}
+			edited, err := FromYAML(tt.edited)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := MergePatch(original, edited); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergePatch = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromYAML(t *testing.T) {
+	for _, text := range []string{"", "# only a comment\n", "- a\n", "a: [\n"} {
+		if _, err := FromYAML(text); err == nil {
+			t.Errorf("FromYAML(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	got, err := ToYAML([]byte(`{"spec":{"project":"default","source":{"path":"a b","targetRevision":"HEAD"}},"list":[1,"2"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "spec:\n  project: default\n  source:\n    path: a b\n    targetRevision: HEAD\nlist:\n- 1\n- \"2\"\n"
+	if got != want {
+		t.Errorf("ToYAML =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"project: default\ndestination: {namespace: ns}\nsource: {repoURL: x}\n", ""},
+		{"project: default\ndestination: {namespace: ns}\nsources: [{repoURL: x}]\n", ""},
+		{"destination: {namespace: ns}\nsource: {repoURL: x}\n", "project is missing"},
+		{"project: \"\"\ndestination: {namespace: ns}\nsource: {repoURL: x}\n", "project is missing"},
+		{"project: default\nsource: {repoURL: x}\n", "destination is missing"},
+		{"project: default\ndestination: in-cluster\nsource: {repoURL: x}\n", "destination is missing"},
+		{"project: default\ndestination: {namespace: ns}\n", "source or sources is missing"},
+	}
+
+	for _, tt := range tests {
+		spec, err := FromYAML(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ValidateSpec(spec)
+		if got := errorText(err); got != tt.want {
+			t.Errorf("ValidateSpec(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	deployment := ApplicationNode{Kind: "Deployment", Group: "apps", Version: "v1", Name: "web", Namespace: "prod"}
+	tests := []struct {
+		manifest string
+		node     ApplicationNode
+		want     string
+	}{
+		{"apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web, namespace: prod}\n", deployment, ""},
+		{"apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: api, namespace: prod}\n", deployment, `metadata.name must stay "web"`},
+		{"apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: web}\n", deployment, `metadata.namespace must stay "prod"`},
+		{"apiVersion: apps/v1\nkind: StatefulSet\nmetadata: {name: web, namespace: prod}\n", deployment, `kind must stay "Deployment"`},
+		{"apiVersion: apps/v2\nkind: Deployment\nmetadata: {name: web, namespace: prod}\n", deployment, `apiVersion must stay "apps/v1"`},
+		{"apiVersion: v1\nkind: Namespace\nmetadata: {name: prod}\n", ApplicationNode{Kind: "Namespace", Version: "v1", Name: "prod"}, ""},
+		{"apiVersion: x/v9\nkind: Pod\nmetadata: {name: p}\n", ApplicationNode{Kind: "Pod", Name: "p"}, ""},
+	}
+
+	for _, tt := range tests {
+		manifest, err := FromYAML(tt.manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ValidateManifest(manifest, tt.node)
+		if got := errorText(err); got != tt.want {
+			t.Errorf("ValidateManifest(%q) = %q, want %q", tt.manifest, got, tt.want)
+		}
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -1,30 +1,105 @@
 package argocd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+var ErrReadonly = errors.New("refusing to change the cluster in read-only mode")
+
 type Service struct {
-	Logger *logrus.Logger
-	Client *http.Client
-	Token  string
+	Logger    *logrus.Logger
+	Client    *http.Client
+	Token     string
+	ServerURL string
+	Readonly  bool
+	// Trees caches resource trees by application name, Apps the application
+	// list.
+	Trees *Cache[[]ApplicationNode]
+	Apps  *Cache[ListApplicationsResponse]
+	// ListOptions narrows the applications listed and watched on the server.
+	ListOptions ListOptions
+}
+
+// ListOptions are the server side filters of the applications API. Fields
+// projects the list response on the given paths and is not sent to the watch
+// stream, which only knows about whole applications.
+type ListOptions struct {
+	Projects     []string
+	Selector     string
+	Repo         string
+	AppNamespace string
+	Fields       []string
+}
+
+// SummaryFields are the parts of an application the TUI shows in its list.
+var SummaryFields = []string{
+	"metadata.resourceVersion",
+	"items.metadata.name",
+	"items.metadata.namespace",
+	"items.metadata.labels",
+	"items.metadata.resourceVersion",
+	"items.spec.project",
+	"items.spec.destination",
+	"items.operation",
+	"items.status.health",
+	"items.status.sync",
+	"items.status.operationState",
+	"items.status.summary",
+}
+
+// Query encodes the options as query parameters, leaving out Fields unless
+// withFields is set.
+func (o ListOptions) Query(withFields bool) url.Values {
+	query := url.Values{}
+
+	for _, project := range o.Projects {
+		query.Add("projects", project)
+	}
+	if o.Selector != "" {
+		query.Set("selector", o.Selector)
+	}
+	if o.Repo != "" {
+		query.Set("repo", o.Repo)
+	}
+	if o.AppNamespace != "" {
+		query.Set("appNamespace", o.AppNamespace)
+	}
+	if withFields && len(o.Fields) > 0 {
+		query.Set("fields", strings.Join(o.Fields, ","))
+	}
+
+	return query
+}
+
+func withQuery(endpoint string, query url.Values) string {
+	if len(query) == 0 {
+		return endpoint
+	}
+
+	return endpoint + "?" + query.Encode()
 }
 
-func NewService(logger *logrus.Logger) *Service {
+func NewService(logger *logrus.Logger, serverURL string, insecure bool) *Service {
 	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
+			InsecureSkipVerify: insecure,
 		},
 	}
 
@@ -33,46 +108,91 @@ func NewService(logger *logrus.Logger) *Service {
 		Timeout:   10 * time.Second,
 	}
 
-	token, err := Login(*client)
+	token, err := Login(*client, serverURL)
 	if err != nil {
 		logger.Fatalf("Error logging in: %v", err)
 	}
 
 	svc := Service{
-		Logger: logger,
-		Client: client,
-		Token:  token,
+		Logger:    logger,
+		Client:    client,
+		Token:     token,
+		ServerURL: serverURL,
+		Trees:     NewCache[[]ApplicationNode](DefaultCacheTTL, DefaultCacheSize),
+		Apps:      NewCache[ListApplicationsResponse](DefaultCacheTTL, 1),
 	}
 
 	return &svc
 }
 
 func (s *Service) Get(path string) (*http.Response, error) {
-	serverURL := os.Getenv("ARGOCD_SERVER_URL")
-	req, err := http.NewRequest(
-		"GET",
-		fmt.Sprintf("%s/api/v1/%s", serverURL, path),
-		nil,
+	return s.Do(http.MethodGet, path, nil)
+}
+
+func (s *Service) Post(path string, body any) (*http.Response, error) {
+	return s.Do(http.MethodPost, path, body)
+}
+
+// Do sends an authenticated request to the ArgoCD API. Responses with a non
+// 2xx status are closed and returned as an error.
+func (s *Service) Do(method string, path string, body any) (*http.Response, error) {
+	return s.do(context.Background(), s.Client, method, "/api/v1/"+path, body)
+}
+
+// do sends a request to endpoint, a path below the server URL.
+func (s *Service) do(ctx context.Context, client *http.Client, method string, endpoint string, body any) (*http.Response, error) {
+	path, _, _ := strings.Cut(strings.TrimPrefix(endpoint, "/api/v1/"), "?")
+
+	if s.Readonly && method != http.MethodGet {
+		return nil, ErrReadonly
+	}
+
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling JSON: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		method,
+		s.ServerURL+endpoint,
+		reader,
 	)
 	if err != nil {
-		s.Logger.Fatalf("Error creating request: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.Token))
 	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	resp, err := s.Client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		log.Fatalf("Error executing request: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+
+		var apiErr APIError
+		err = json.NewDecoder(resp.Body).Decode(&apiErr)
+		if err != nil || apiErr.Message == "" {
+			return nil, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, apiErr.Message)
 	}
 
 	return resp, nil
 }
 
-func Login(client http.Client) (string, error) {
-	serverURL := os.Getenv("ARGOCD_SERVER_URL")
+func Login(client http.Client, serverURL string) (string, error) {
 	loginBody := map[string]string{
 		"password": os.Getenv("ARGOCD_PASSWORD"),
 		"username": os.Getenv("ARGOCD_USERNAME"),
@@ -110,10 +230,10 @@ func Login(client http.Client) (string, error) {
 	return loginToken.Token, nil
 }
 
-func (s *Service) ListApplications() ListApplicationsResponse {
-	resp, err := s.Get("applications")
+func (s *Service) ListApplicationsContext(ctx context.Context) (*ListApplicationsResponse, error) {
+	resp, err := s.do(ctx, s.Client, http.MethodGet, withQuery("/api/v1/applications", s.ListOptions.Query(true)), nil)
 	if err != nil {
-		log.Fatalf("Could not get applications: %v", err)
+		return nil, err
 	}
 
 	defer resp.Body.Close()
@@ -122,7 +242,7 @@ func (s *Service) ListApplications() ListApplicationsResponse {
 
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
-		log.Fatalf("Error decoding json: %v", err)
+		return nil, fmt.Errorf("error decoding json: %w", err)
 	}
 
 	debugResult, err := json.Marshal(result)
@@ -132,14 +252,100 @@ func (s *Service) ListApplications() ListApplicationsResponse {
 		return result.Items[i].Metadata.Name < result.Items[j].Metadata.Name
 	})
 
-	return result
+	version, _ := result.Metadata["resourceVersion"].(string)
+	s.Apps.Put(applicationsCacheKey, version, result)
+
+	return &result, nil
+}
+
+const applicationsCacheKey = "applications"
+
+// CachedApplications returns the last listed applications and whether they
+// are younger than the TTL.
+func (s *Service) CachedApplications() (CacheEntry[ListApplicationsResponse], bool, bool) {
+	entry, ok := s.Apps.Get(applicationsCacheKey)
+	return entry, ok && s.Apps.Fresh(entry, entry.Version, time.Now()), ok
+}
+
+// ResourceTree fetches the resource tree of application and caches it for
+// version, the resourceVersion of the application.
+func (s *Service) ResourceTree(ctx context.Context, application string, version string) ([]ApplicationNode, error) {
+	nodes, err := s.GetResourceTreeContext(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Trees.Put(application, version, nodes)
+	return nodes, nil
+}
+
+// CachedResourceTree returns the last fetched resource tree of application
+// and whether it is still fresh for version.
+func (s *Service) CachedResourceTree(application string, version string) (CacheEntry[[]ApplicationNode], bool, bool) {
+	entry, ok := s.Trees.Get(application)
+	return entry, ok && s.Trees.Fresh(entry, version, time.Now()), ok
+}
+
+// TreeWorkers resource trees are fetched at once by FetchTrees, and a new
+// request starts at most every TreeInterval, so looking across a large
+// installation does not flood the server.
+const (
+	TreeWorkers  = 4
+	TreeInterval = 50 * time.Millisecond
+)
+
+// TreeRequest names an application whose tree FetchTrees fetches, and the
+// resourceVersion to cache it for.
+type TreeRequest struct {
+	Name    string
+	Version string
+}
+
+// FetchTrees fetches the resource trees of requests and calls done with each
+// of them, from several goroutines at once. It returns once every tree was
+// fetched or ctx is done.
+func (s *Service) FetchTrees(ctx context.Context, requests []TreeRequest, done func(TreeRequest, []ApplicationNode, error)) {
+	jobs := make(chan TreeRequest)
+	go func() {
+		defer close(jobs)
+
+		ticker := time.NewTicker(TreeInterval)
+		defer ticker.Stop()
+
+		for _, request := range requests {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- request:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range TreeWorkers {
+		wg.Go(func() {
+			for request := range jobs {
+				nodes, err := s.ResourceTree(ctx, request.Name, request.Version)
+				if ctx.Err() != nil {
+					return
+				}
+				done(request, nodes, err)
+			}
+		})
+	}
+	wg.Wait()
 }
 
-func (s *Service) GetResourceTree(application string) []ApplicationNode {
-	resp, err := s.Get(fmt.Sprintf("applications/%s/resource-tree", application))
+func (s *Service) GetResourceTreeContext(ctx context.Context, application string) ([]ApplicationNode, error) {
+	resp, err := s.do(ctx, s.Client, http.MethodGet, fmt.Sprintf("/api/v1/applications/%s/resource-tree", url.PathEscape(application)), nil)
 	if err != nil {
-		s.Logger.Fatalf("Error executing request: %v", err)
-		return nil
+		return nil, err
 	}
 
 	defer resp.Body.Close()
@@ -148,12 +354,166 @@ func (s *Service) GetResourceTree(application string) []ApplicationNode {
 
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
-		s.Logger.Fatalf("Error with json decoder: %v", err)
+		return nil, fmt.Errorf("error with json decoder: %w", err)
 	}
 
 	sort.Slice(result.Nodes, func(i, j int) bool {
 		return result.Nodes[i].Name < result.Nodes[j].Name
 	})
 
-	return result.Nodes
+	return result.Nodes, nil
+}
+
+func (s *Service) GetApplication(name string) (*ApplicationItem, error) {
+	resp, err := s.Get(fmt.Sprintf("applications/%s", url.PathEscape(name)))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var result ApplicationItem
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (s *Service) SyncApplication(name string) (*ApplicationItem, error) {
+	resp, err := s.Post(
+		fmt.Sprintf("applications/%s/sync", url.PathEscape(name)),
+		SyncRequest{Name: name},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var result ApplicationItem
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (s *Service) ListEvents(application string) ([]Event, error) {
+	resp, err := s.Get(fmt.Sprintf("applications/%s/events", url.PathEscape(application)))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var result EventList
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].LastTimestamp.Before(result.Items[j].LastTimestamp)
+	})
+
+	return result.Items, nil
+}
+
+func (s *Service) GetUserInfo() (*UserInfo, error) {
+	resp, err := s.Get("session/userinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var result UserInfo
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetVersion returns the version of the ArgoCD server, which is served
+// outside of /api/v1.
+func (s *Service) GetVersion() (*VersionMessage, error) {
+	resp, err := s.do(context.Background(), s.Client, http.MethodGet, "/api/version", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	var result VersionMessage
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	return &result, nil
+}
+
+// WatchApplications streams changes to the applications until ctx is
+// cancelled or the connection breaks. Given the resourceVersion of a list,
+// the stream only sends the changes after it, otherwise it starts with an
+// ADDED event for every application. onConnect runs once the server accepted
+// the stream.
+func (s *Service) WatchApplications(ctx context.Context, version string, onConnect func(), onEvent func(ApplicationWatchEvent)) error {
+	// the client timeout would cut the stream off
+	client := *s.Client
+	client.Timeout = 0
+
+	query := s.ListOptions.Query(false)
+	if version != "" {
+		query.Set("resourceVersion", version)
+	}
+
+	resp, err := s.do(ctx, &client, http.MethodGet, withQuery("/api/v1/stream/applications", query), nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	onConnect()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var message StreamMessage
+		err = json.Unmarshal(line, &message)
+		if err != nil {
+			return fmt.Errorf("error decoding stream: %w", err)
+		}
+
+		if message.Error != nil {
+			return fmt.Errorf("stream error: %s", message.Error.Message)
+		}
+
+		if message.Result != nil {
+			onEvent(*message.Result)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.ErrUnexpectedEOF
 }
@@ -13,9 +13,18 @@ type ListApplicationsResponse struct {
 	Metadata map[string]any    `json:"metadata"`
 }
 
+type APIError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
 type ApplicationMetadata struct {
-	Name   string `json:"name"`
-	Status string `json:"status"`
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Status          string            `json:"status,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
 }
 
 type ApplicationHealthStatus string
@@ -26,12 +35,57 @@ const (
 	StatusProgressing ApplicationHealthStatus = "Progressing"
 	StatusUnknown     ApplicationHealthStatus = "Unknown"
 	StatusDegraded    ApplicationHealthStatus = "Degraded"
+	StatusSuspended   ApplicationHealthStatus = "Suspended"
+)
+
+type ApplicationSyncStatus string
+
+const (
+	SyncStatusSynced    ApplicationSyncStatus = "Synced"
+	SyncStatusOutOfSync ApplicationSyncStatus = "OutOfSync"
+	SyncStatusUnknown   ApplicationSyncStatus = "Unknown"
+)
+
+type OperationPhase string
+
+const (
+	OperationRunning     OperationPhase = "Running"
+	OperationTerminating OperationPhase = "Terminating"
+	OperationFailed      OperationPhase = "Failed"
+	OperationError       OperationPhase = "Error"
+	OperationSucceeded   OperationPhase = "Succeeded"
 )
 
 type ApplicationStatus struct {
 	Health struct {
 		Status ApplicationHealthStatus `json:"status"`
 	} `json:"health"`
+	Sync struct {
+		Status   ApplicationSyncStatus `json:"status"`
+		Revision string                `json:"revision,omitempty"`
+	} `json:"sync"`
+	OperationState *OperationState `json:"operationState,omitempty"`
+	Summary        struct {
+		Images []string `json:"images,omitempty"`
+	} `json:"summary"`
+	Resources []ResourceStatus `json:"resources,omitempty"`
+}
+
+// ResourceStatus is the sync state of a resource managed by an application.
+type ResourceStatus struct {
+	Group     string                `json:"group,omitempty"`
+	Version   string                `json:"version"`
+	Kind      string                `json:"kind"`
+	Namespace string                `json:"namespace,omitempty"`
+	Name      string                `json:"name"`
+	Status    ApplicationSyncStatus `json:"status"`
+}
+
+type OperationState struct {
+	Phase      OperationPhase `json:"phase"`
+	Message    string         `json:"message,omitempty"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt *time.Time     `json:"finishedAt,omitempty"`
 }
 
 type ApplicationItem struct {
@@ -41,6 +95,35 @@ type ApplicationItem struct {
 	Status    ApplicationStatus   `json:"status"`
 }
 
+// Project returns the project of the application from its spec.
+func (a ApplicationItem) Project() string {
+	project, _ := a.Spec["project"].(string)
+	return project
+}
+
+// DestinationNamespace returns the namespace the application deploys to.
+func (a ApplicationItem) DestinationNamespace() string {
+	destination, _ := a.Spec["destination"].(map[string]any)
+	namespace, _ := destination["namespace"].(string)
+	return namespace
+}
+
+// ResourceSync maps the resources managed by the application, keyed with
+// ResourceKey, to their sync state. Resources owned by other resources, like
+// the pods of a deployment, have none.
+func (a ApplicationItem) ResourceSync() map[string]ApplicationSyncStatus {
+	sync := make(map[string]ApplicationSyncStatus, len(a.Status.Resources))
+	for _, resource := range a.Status.Resources {
+		sync[ResourceKey(resource.Group, resource.Kind, resource.Namespace, resource.Name)] = resource.Status
+	}
+	return sync
+}
+
+// ResourceKey identifies a resource within an application.
+func ResourceKey(group, kind, namespace, name string) string {
+	return group + "/" + kind + "/" + namespace + "/" + name
+}
+
 type ParentRef struct {
 	Group     string `json:"group"`
 	Version   string `json:"version"`
@@ -56,11 +139,14 @@ type InfoItem struct {
 }
 
 type NetworkingInfo struct {
-	Labels map[string]string `json:"labels"`
+	Labels       map[string]string `json:"labels"`
+	TargetLabels map[string]string `json:"targetLabels,omitempty"`
+	ExternalURLs []string          `json:"externalURLs,omitempty"`
 }
 
 type Health struct {
-	Status string `json:"status"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
 }
 
 type ResourceTreeResponse struct {
@@ -68,6 +154,7 @@ type ResourceTreeResponse struct {
 }
 
 type ApplicationNode struct {
+	Group           string         `json:"group"`
 	Version         string         `json:"version"`
 	Kind            string         `json:"kind"`
 	Namespace       string         `json:"namespace"`
@@ -81,3 +168,95 @@ type ApplicationNode struct {
 	Health          Health         `json:"health"`
 	CreatedAt       time.Time      `json:"createdAt"`
 }
+
+type SyncRequest struct {
+	Name     string `json:"name"`
+	Revision string `json:"revision,omitempty"`
+	Prune    bool   `json:"prune,omitempty"`
+	DryRun   bool   `json:"dryRun,omitempty"`
+}
+
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type Event struct {
+	Type           string          `json:"type"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Count          int             `json:"count"`
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+}
+
+type EventList struct {
+	Items []Event `json:"items"`
+}
+
+type UserInfo struct {
+	LoggedIn bool     `json:"loggedIn"`
+	Username string   `json:"username"`
+	Issuer   string   `json:"iss"`
+	Groups   []string `json:"groups"`
+}
+
+type VersionMessage struct {
+	Version   string `json:"Version"`
+	BuildDate string `json:"BuildDate"`
+}
+
+type WatchEventType string
+
+const (
+	WatchAdded    WatchEventType = "ADDED"
+	WatchModified WatchEventType = "MODIFIED"
+	WatchDeleted  WatchEventType = "DELETED"
+)
+
+type ApplicationWatchEvent struct {
+	Type        WatchEventType  `json:"type"`
+	Application ApplicationItem `json:"application"`
+}
+
+// StreamMessage is one line of a streaming response.
+type StreamMessage struct {
+	Result *ApplicationWatchEvent `json:"result"`
+	Error  *APIError              `json:"error"`
+}
+
+// Owners follows the first parent of node up through tree and returns the
+// chain from the outermost owner down to its direct parent. A parent that is
+// not part of tree ends the chain.
+func Owners(node ApplicationNode, tree []ApplicationNode) []ParentRef {
+	key := func(kind, namespace, name string) string {
+		return kind + "/" + namespace + "/" + name
+	}
+
+	nodes := map[string]ApplicationNode{}
+	for _, n := range tree {
+		nodes[key(n.Kind, n.Namespace, n.Name)] = n
+	}
+
+	owners := []ParentRef{}
+	seen := map[string]bool{key(node.Kind, node.Namespace, node.Name): true}
+	for len(node.ParentRefs) > 0 {
+		parent := node.ParentRefs[0]
+		k := key(parent.Kind, parent.Namespace, parent.Name)
+		if seen[k] {
+			break
+		}
+		seen[k] = true
+		owners = append([]ParentRef{parent}, owners...)
+
+		next, ok := nodes[k]
+		if !ok {
+			break
+		}
+		node = next
+	}
+
+	return owners
+}
@@ -0,0 +1,110 @@
+package clipboard
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Auto picks the first of Commands found on the PATH.
+const Auto = "auto"
+
+// Commands are the clipboard tools tried by Auto, for Wayland, X11 and macOS.
+var Commands = []string{
+	"wl-copy",
+	"xclip -selection clipboard",
+	"pbcopy",
+}
+
+// Clipboard copies text with an OSC 52 escape sequence, which the terminal
+// turns into a clipboard update even over SSH, and with Command when one is
+// configured.
+type Clipboard struct {
+	OSC52   bool
+	Command string
+}
+
+// CommandTimeout is how long Run waits for the clipboard command before it is
+// killed.
+const CommandTimeout = 5 * time.Second
+
+// Copy writes the OSC 52 sequence for text to the terminal, if enabled.
+func (c Clipboard) Copy(terminal io.Writer, text string) error {
+	if !c.OSC52 || terminal == nil {
+		return nil
+	}
+
+	_, err := io.WriteString(terminal, OSC52(text))
+	if err != nil {
+		return fmt.Errorf("error writing to the terminal: %w", err)
+	}
+
+	return nil
+}
+
+// Run pipes text to the command, if one is configured. It blocks until the
+// command exits or CommandTimeout passed.
+func (c Clipboard) Run(text string) error {
+	if c.Command == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CommandTimeout)
+	defer cancel()
+
+	return run(ctx, c.Command, text)
+}
+
+// OSC52 returns the escape sequence that sets the clipboard of the terminal
+// to text.
+func OSC52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+// Resolve returns the command line to run for command, looking up the tools
+// for Auto.
+func Resolve(command string) ([]string, error) {
+	if command != Auto {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			return nil, errors.New("empty clipboard command")
+		}
+		return fields, nil
+	}
+
+	for _, candidate := range Commands {
+		fields := strings.Fields(candidate)
+		if _, err := exec.LookPath(fields[0]); err == nil {
+			return fields, nil
+		}
+	}
+
+	return nil, fmt.Errorf("none of %s found", strings.Join(Commands, ", "))
+}
+
+func run(ctx context.Context, command string, text string) error {
+	fields, err := Resolve(command)
+	if err != nil {
+		return err
+	}
+
+	// no output is captured, wl-copy and xclip leave a process behind that
+	// serves the clipboard and would keep the pipes open
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s did not finish within %s", fields[0], CommandTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("error running %s: %w", fields[0], err)
+	}
+
+	return nil
+}
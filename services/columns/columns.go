@@ -0,0 +1,182 @@
+package columns
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/main/services/argocd"
+	"example.com/main/services/search"
+	"example.com/main/services/utils"
+)
+
+// The columns a table of resources can show.
+const (
+	Name            = "Name"
+	Kind            = "Kind"
+	Group           = "Group"
+	Health          = "Health"
+	SyncStatus      = "Sync Status"
+	Namespace       = "Namespace"
+	Version         = "Version"
+	ResourceVersion = "Resource Version"
+	Images          = "Images"
+	Info            = "Info"
+	Age             = "Age"
+	Restarts        = "Restarts"
+)
+
+// Resources are the columns known for resources, in the order the config
+// problems list them.
+var Resources = []string{Name, Kind, Group, Health, SyncStatus, Namespace, Version, ResourceVersion, Images, Info, Age, Restarts}
+
+// Views are the tables whose columns can be configured: the resources of the
+// selected application and the results of the global search.
+var Views = []string{"resources", "search"}
+
+// Column is a column of a table as configured. Width caps it, 0 fits it to
+// its content.
+type Column struct {
+	Name  string
+	Width int
+}
+
+// Defaults are the columns of each view when the config sets none.
+var Defaults = map[string][]Column{
+	"resources": {{Name: Name}, {Name: Kind}, {Name: Health}, {Name: Namespace}, {Name: Version}, {Name: ResourceVersion}, {Name: Images}, {Name: Info}},
+	"search":    {{Name: Kind}, {Name: Namespace}, {Name: Name}, {Name: Images}},
+}
+
+// Sort is the column a table is sorted by.
+type Sort struct {
+	Column     string `yaml:"column"`
+	Descending bool   `yaml:"descending"`
+}
+
+// Lookup finds the column called name, ignoring case.
+func Lookup(name string) (string, bool) {
+	for _, column := range Resources {
+		if strings.EqualFold(column, name) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// Resource is a row of a table of resources, the node and the sync state its
+// application reports for it.
+type Resource struct {
+	Node argocd.ApplicationNode
+	Sync argocd.ApplicationSyncStatus
+}
+
+// Value returns the text of column for r, and the search field whose matches
+// are highlighted in it.
+func (r Resource) Value(column string) (string, string) {
+	node := r.Node
+
+	switch column {
+	case Name:
+		return node.Name, "name"
+	case Kind:
+		return node.Kind, "kind"
+	case Group:
+		return node.Group, ""
+	case Health:
+		return node.Health.Status, "health"
+	case SyncStatus:
+		return string(r.Sync), ""
+	case Namespace:
+		return node.Namespace, "ns"
+	case Version:
+		return node.Version, ""
+	case ResourceVersion:
+		return node.ResourceVersion, ""
+	case Images:
+		return strings.Join(node.Images, ", "), "image"
+	case Info:
+		return strings.Join(search.Info(node), ", "), "info"
+	case Age:
+		if node.CreatedAt.IsZero() {
+			return "", ""
+		}
+		return utils.ShortDuration(time.Since(node.CreatedAt)), ""
+	case Restarts:
+		if restarts, ok := r.restarts(); ok {
+			return strconv.Itoa(restarts), ""
+		}
+		return "", ""
+	}
+
+	return "", ""
+}
+
+// restarts sums the restart counts in the info items of a pod.
+func (r Resource) restarts() (int, bool) {
+	total, found := 0, false
+	for _, item := range r.Node.Info {
+		if item.Name != "Restart Count" {
+			continue
+		}
+		if count, err := strconv.Atoi(item.Value); err == nil {
+			total += count
+			found = true
+		}
+	}
+	return total, found
+}
+
+// Numeric reports whether column is aligned and sorted as a number.
+func Numeric(column string) bool {
+	return column == Age || column == Restarts
+}
+
+// SortResources orders rows by the column of sort, ties by name and kind. An
+// unknown column keeps the order.
+func SortResources(rows []Resource, sort Sort) {
+	if _, ok := Lookup(sort.Column); !ok {
+		return
+	}
+
+	slices.SortStableFunc(rows, func(a, b Resource) int {
+		order := a.compare(b, sort.Column)
+		if sort.Descending {
+			order = -order
+		}
+		if order != 0 {
+			return order
+		}
+		return cmp.Or(strings.Compare(a.Node.Name, b.Node.Name), strings.Compare(a.Node.Kind, b.Node.Kind))
+	})
+}
+
+func (r Resource) compare(other Resource, column string) int {
+	switch column {
+	case Age:
+		// the youngest first, resources without a creation time last
+		if r.Node.CreatedAt.IsZero() || other.Node.CreatedAt.IsZero() {
+			return cmp.Compare(boolInt(r.Node.CreatedAt.IsZero()), boolInt(other.Node.CreatedAt.IsZero()))
+		}
+		return other.Node.CreatedAt.Compare(r.Node.CreatedAt)
+	case Restarts:
+		a, aok := r.restarts()
+		b, bok := other.restarts()
+		if aok != bok {
+			return cmp.Compare(boolInt(!aok), boolInt(!bok))
+		}
+		return cmp.Compare(a, b)
+	}
+
+	a, _ := r.Value(column)
+	b, _ := other.Value(column)
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
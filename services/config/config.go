@@ -1,18 +1,32 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
-	"example.com/main/services/utils"
-	"github.com/gdamore/tcell/v2"
+	"example.com/main/services/argocd"
+	"example.com/main/services/clipboard"
+	"example.com/main/services/columns"
+	"example.com/main/services/keys"
+	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
 const ARGO_CONFIG_DIR = "argocd-tui"
 
-func NewConfig() *Config {
+// DefaultPageSize is how many applications are rendered before scrolling to
+// the end of the list renders more. 0 renders all of them.
+const DefaultPageSize = 100
+
+func DefaultPath() string {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		log.Fatalf("Could not find config dir: %v", err)
@@ -22,47 +36,265 @@ func NewConfig() *Config {
 		configDir = os.Getenv("XDG_CONFIG_HOME")
 	}
 
-	path := fmt.Sprintf("%s/argocd-tui/config.yaml", configDir)
+	return fmt.Sprintf("%s/%s/config.yaml", configDir, ARGO_CONFIG_DIR)
+}
 
-	_, err = os.Stat(path)
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(fmt.Sprintf("%s/%s", configDir, ARGO_CONFIG_DIR), 0755)
-		if err != nil {
-			log.Fatalf("Error creating directory: %v", err)
-		}
+// NewConfig loads the config file at path. An empty path loads the default
+// config file, which is created when it does not exist yet.
+func NewConfig(path string) *Config {
+	if path == "" {
+		path = DefaultPath()
 
-		file, err := os.Create(path)
-		if err != nil {
-			log.Fatalf("Error creating file %s: %v", path, err)
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+			if err != nil {
+				log.Fatalf("Error creating directory: %v", err)
+			}
+
+			file, err := os.Create(path)
+			if err != nil {
+				log.Fatalf("Error creating file %s: %v", path, err)
+			}
+
+			defer file.Close()
 		}
+	}
 
-		defer file.Close()
+	config, err := Load(path)
+	if err != nil {
+		log.Fatalf("Error reading file: %v", err)
 	}
 
+	return config
+}
+
+// Load reads and validates the config file at path. Only a file that cannot
+// be read is an error, everything else is collected in Problems.
+func Load(path string) (*Config, error) {
 	fileBytes, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Error reading file: %v", err)
+		return nil, err
+	}
+
+	externalConfig := Config{
+		Path: path,
+		root: &yaml.Node{},
 	}
 
 	var config InternalConfig
 
-	err = yaml.Unmarshal(fileBytes, &config)
+	err = yaml.Unmarshal(fileBytes, externalConfig.root)
 	if err != nil {
-		log.Fatalf("Error unmarshaling yaml: %v", err)
+		externalConfig.Problems = append(externalConfig.Problems, Problem{Message: err.Error()})
+	} else if externalConfig.root.Kind != 0 {
+		err = externalConfig.root.Decode(&config)
+
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			for _, message := range typeErr.Errors {
+				externalConfig.Problems = append(externalConfig.Problems, Problem{Message: message})
+			}
+		}
+
+		externalConfig.Problems = append(
+			externalConfig.Problems,
+			unknownKeys(externalConfig.root, reflect.TypeOf(config), "")...,
+		)
 	}
 
-	externalConfig := Config{
-		Background:  utils.HexToColor(config.Colors.Background, tcell.ColorDefault),
-		Border:      utils.HexToColor(config.Colors.Border, tcell.ColorDarkSlateGray),
-		Selected:    utils.HexToColor(config.Colors.Selected, tcell.ColorSkyblue),
-		Header:      utils.HexToColor(config.Colors.Header, tcell.ColorGray),
-		Text:        utils.HexToColor(config.Colors.Text, tcell.ColorWhite),
-		Foreground:  utils.HexToColor(config.Colors.Foreground, tcell.ColorWhiteSmoke),
-		Progressing: utils.HexToColor(config.Colors.Progressing, tcell.ColorLightBlue),
-		Missing:     utils.HexToColor(config.Colors.Missing, tcell.ColorLightYellow),
-		Healthy:     utils.HexToColor(config.Colors.Healthy, tcell.ColorLightGreen),
-		Degraded:    utils.HexToColor(config.Colors.Degraded, tcell.ColorIndianRed),
-	}
-
-	return &externalConfig
+	externalConfig.Theme = config.Theme
+	externalConfig.colors = config.Colors
+	externalConfig.styles = config.Styles
+	externalConfig.applyColors()
+
+	externalConfig.Keys = externalConfig.parseKeys(config.Keys)
+	externalConfig.Context = config.Context
+	externalConfig.Contexts = config.Contexts
+	externalConfig.LogLevel = config.Log.Level
+	externalConfig.LogFile = config.Log.File
+
+	externalConfig.CacheTTL = argocd.DefaultCacheTTL
+	if config.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(config.Cache.TTL)
+		if err != nil || ttl < 0 {
+			externalConfig.Problems = append(externalConfig.Problems, externalConfig.Problem(
+				fmt.Sprintf("invalid duration %q, expected a value like 30s or 5m", config.Cache.TTL),
+				"cache", "ttl",
+			))
+		} else {
+			externalConfig.CacheTTL = ttl
+		}
+	}
+
+	externalConfig.CacheSize = argocd.DefaultCacheSize
+	if config.Cache.Size != nil {
+		if *config.Cache.Size < 0 {
+			externalConfig.Problems = append(externalConfig.Problems, externalConfig.Problem("size must not be negative", "cache", "size"))
+		} else {
+			externalConfig.CacheSize = *config.Cache.Size
+		}
+	}
+
+	externalConfig.ListOptions = argocd.ListOptions{
+		Projects:     config.Applications.Projects,
+		Selector:     config.Applications.Selector,
+		Repo:         config.Applications.Repo,
+		AppNamespace: config.Applications.AppNamespace,
+	}
+
+	externalConfig.PageSize = DefaultPageSize
+	if config.Applications.PageSize != nil {
+		if *config.Applications.PageSize < 0 {
+			externalConfig.Problems = append(externalConfig.Problems, externalConfig.Problem("pageSize must not be negative", "applications", "pageSize"))
+		} else {
+			externalConfig.PageSize = *config.Applications.PageSize
+		}
+	}
+
+	externalConfig.Columns = externalConfig.parseColumns(config.Columns)
+
+	externalConfig.Clipboard = clipboard.Clipboard{
+		OSC52:   config.Clipboard.OSC52 == nil || *config.Clipboard.OSC52,
+		Command: strings.TrimSpace(config.Clipboard.Command),
+	}
+	externalConfig.Editor = strings.TrimSpace(config.Editor)
+
+	for _, name := range externalConfig.ContextNames() {
+		err := validateServerURL(config.Contexts[name].Server)
+		if err != nil {
+			externalConfig.Problems = append(externalConfig.Problems, externalConfig.Problem(
+				fmt.Sprintf("invalid server URL %q: %v", config.Contexts[name].Server, err),
+				"contexts", name, "server",
+			))
+		}
+	}
+
+	if _, ok := config.Contexts[config.Context]; config.Context != "" && !ok {
+		externalConfig.Problems = append(externalConfig.Problems, externalConfig.Problem(
+			fmt.Sprintf("unknown context %q", config.Context),
+			"context",
+		))
+	}
+
+	if config.Log.Level != "" {
+		_, err := logrus.ParseLevel(config.Log.Level)
+		if err != nil {
+			externalConfig.Problems = append(externalConfig.Problems, externalConfig.Problem(err.Error(), "log", "level"))
+		}
+	}
+
+	return &externalConfig, nil
+}
+
+// parseColumns checks the columns configured per view and fills in the
+// default columns of the other views. Unknown and repeated columns are
+// reported as problems and left out.
+func (c *Config) parseColumns(raw map[string][]ColumnConfig) map[string][]columns.Column {
+	views := map[string][]columns.Column{}
+	for view, defaults := range columns.Defaults {
+		views[view] = defaults
+	}
+
+	names := make([]string, 0, len(raw))
+	for view := range raw {
+		names = append(names, view)
+	}
+	sort.Strings(names)
+
+	for _, view := range names {
+		if !slices.Contains(columns.Views, view) {
+			c.Problems = append(c.Problems, c.Problem(
+				fmt.Sprintf("unknown view %q, expected one of %s", view, strings.Join(columns.Views, ", ")),
+				"columns", view,
+			))
+			continue
+		}
+
+		configured := []columns.Column{}
+		for _, column := range raw[view] {
+			problem := func(message string) {
+				c.Problems = append(c.Problems, Problem{
+					Line:    column.node.Line,
+					Column:  column.node.Column,
+					Path:    "columns." + view,
+					Message: message,
+				})
+			}
+
+			name, ok := columns.Lookup(column.Name)
+			switch {
+			case !ok:
+				problem(fmt.Sprintf("unknown column %q, expected one of %s", column.Name, strings.Join(columns.Resources, ", ")))
+				continue
+			case column.Width < 0:
+				problem(fmt.Sprintf("width of %s must not be negative", name))
+				continue
+			case slices.ContainsFunc(configured, func(other columns.Column) bool { return other.Name == name }):
+				problem(fmt.Sprintf("column %s is listed twice", name))
+				continue
+			}
+
+			configured = append(configured, columns.Column{Name: name, Width: column.Width})
+		}
+
+		if len(configured) > 0 {
+			views[view] = configured
+		}
+	}
+
+	return views
+}
+
+// parseKeys parses the keys section, which maps a context to action IDs and
+// their key specs. Invalid specs and two actions bound to the same keys in
+// one context are reported as problems and left out. Context names are case
+// insensitive.
+func (c *Config) parseKeys(raw map[string]map[string]string) map[string]map[string]keys.Sequence {
+	bindings := map[string]map[string]keys.Sequence{}
+
+	contexts := make([]string, 0, len(raw))
+	for ctx := range raw {
+		contexts = append(contexts, ctx)
+	}
+	sort.Strings(contexts)
+
+	for _, ctx := range contexts {
+		name := strings.ToLower(ctx)
+		if _, ok := bindings[name]; !ok {
+			bindings[name] = map[string]keys.Sequence{}
+		}
+
+		actions := make([]string, 0, len(raw[ctx]))
+		for action := range raw[ctx] {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+
+		bound := map[string]string{}
+		for id, seq := range bindings[name] {
+			bound[seq.String()] = id
+		}
+
+		for _, action := range actions {
+			seq, err := keys.Parse(raw[ctx][action])
+			if err != nil {
+				c.Problems = append(c.Problems, c.Problem(err.Error(), "keys", ctx, action))
+				continue
+			}
+
+			if other, ok := bound[seq.String()]; ok {
+				c.Problems = append(c.Problems, c.Problem(
+					fmt.Sprintf("%q is already bound to %q", seq, other),
+					"keys", ctx, action,
+				))
+				continue
+			}
+
+			bound[seq.String()] = action
+			bindings[name][action] = seq
+		}
+	}
+
+	return bindings
 }
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	EnvServer   = "ARGOCD_SERVER_URL"
+	EnvContext  = "ARGOCD_TUI_CONTEXT"
+	EnvConfig   = "ARGOCD_TUI_CONFIG"
+	EnvLogLevel = "ARGOCD_TUI_LOG_LEVEL"
+	EnvLogFile  = "ARGOCD_TUI_LOG_FILE"
+	EnvInsecure = "ARGOCD_TUI_INSECURE"
+	EnvReadonly = "ARGOCD_TUI_READONLY"
+	EnvApp      = "ARGOCD_TUI_APP"
+)
+
+const (
+	DefaultLogLevel = "info"
+	DefaultLogFile  = "debug.json"
+)
+
+// DefaultEditor is run when neither the config file, VISUAL nor EDITOR name
+// an editor.
+const DefaultEditor = "vi"
+
+// EditorCommand returns the editor from the config file, or from VISUAL or
+// EDITOR like git does, split into its arguments.
+func (c *Config) EditorCommand() []string {
+	for _, editor := range []string{c.Editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			return fields
+		}
+	}
+
+	return []string{DefaultEditor}
+}
+
+// Options are the settings that can be given as command line flags, as
+// environment variables or in the config file.
+type Options struct {
+	Server    string
+	Context   string
+	LogLevel  string
+	LogFile   string
+	Insecure  bool
+	Readonly  bool
+	Protected bool
+	App       string
+}
+
+// ResolvePath returns the config file to load, from the --config flag or
+// ARGOCD_TUI_CONFIG. An empty result means the default path.
+func ResolvePath(flags map[string]string) string {
+	if path, ok := flags["config"]; ok {
+		return path
+	}
+
+	return os.Getenv(EnvConfig)
+}
+
+// ResolveOptions merges the flags that were set on the command line, keyed by
+// flag name, with the environment, the config file and the defaults, in that
+// order of precedence. Server settings come from the selected context of the
+// config file. A context picked with --context counts as a flag, so its server
+// wins over ARGOCD_SERVER_URL. The settings of a context only apply when its
+// server is used, and read-only is on when any layer turns it on.
+func ResolveOptions(flags map[string]string, cfg *Config) (Options, error) {
+	contextName := lookup(flags, "context", EnvContext, cfg.Context, "")
+	_, contextFromFlag := flags["context"]
+
+	profile := ContextConfig{}
+	if contextName != "" {
+		var ok bool
+		profile, ok = cfg.Contexts[contextName]
+		switch {
+		case !ok && contextName == cfg.Context && os.Getenv(EnvContext) == "" && !contextFromFlag:
+			// already reported as a problem of the config file
+			contextName = ""
+		case !ok:
+			return Options{}, fmt.Errorf("unknown context %q, known contexts: %s", contextName, strings.Join(cfg.ContextNames(), ", "))
+		}
+	}
+
+	if _, ok := flags["server"]; contextFromFlag && !ok && profile.Server != "" {
+		flags["server"] = profile.Server
+	}
+	server := strings.TrimSuffix(lookup(flags, "server", EnvServer, profile.Server, ""), "/")
+
+	// the settings of a profile describe its server, ARGOCD_SERVER_URL or
+	// --server may point somewhere else
+	if profile.Server == "" || strings.TrimSuffix(profile.Server, "/") != server {
+		profile = ContextConfig{}
+	}
+
+	if _, ok := flags["insecure"]; contextFromFlag && !ok && profile.Insecure != nil {
+		flags["insecure"] = strconv.FormatBool(*profile.Insecure)
+	}
+
+	insecure, err := strconv.ParseBool(lookup(flags, "insecure", EnvInsecure, formatBool(profile.Insecure), "false"))
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid insecure setting: %w", err)
+	}
+
+	// every layer can turn read-only on, none can turn it off
+	readonly := profile.Readonly != nil && *profile.Readonly
+	for _, value := range []string{flags["readonly"], os.Getenv(EnvReadonly)} {
+		if value == "" {
+			continue
+		}
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid readonly setting: %w", err)
+		}
+		readonly = readonly || on
+	}
+
+	// an invalid level in the file is reported as a problem, use the default
+	fileLogLevel := cfg.LogLevel
+	if _, err := logrus.ParseLevel(fileLogLevel); err != nil {
+		fileLogLevel = ""
+	}
+
+	return Options{
+		Server:    server,
+		Context:   contextName,
+		LogLevel:  lookup(flags, "log-level", EnvLogLevel, fileLogLevel, DefaultLogLevel),
+		LogFile:   lookup(flags, "log-file", EnvLogFile, cfg.LogFile, DefaultLogFile),
+		Insecure:  insecure,
+		Readonly:  readonly,
+		Protected: profile.Protected,
+		App:       lookup(flags, "app", EnvApp, "", ""),
+	}, nil
+}
+
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookup(flags map[string]string, flag string, env string, file string, def string) string {
+	if value, ok := flags[flag]; ok {
+		return value
+	}
+
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+
+	if file != "" {
+		return file
+	}
+
+	return def
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"example.com/main/services/columns"
+	"gopkg.in/yaml.v3"
+)
+
+// State is what the TUI remembers between sessions. It is kept apart from
+// the config file, so saving it never rewrites what the user wrote.
+type State struct {
+	Sort map[string]columns.Sort `yaml:"sort,omitempty"`
+	path string
+}
+
+// DefaultStatePath is state.yaml in $XDG_STATE_HOME/argocd-tui, or in
+// ~/.local/state/argocd-tui.
+func DefaultStatePath() string {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(stateDir, ARGO_CONFIG_DIR, "state.yaml")
+}
+
+// LoadState reads the state file at path. A missing file is an empty state,
+// a broken one is an empty state and an error.
+func LoadState(path string) (*State, error) {
+	state := &State{Sort: map[string]columns.Sort{}, path: path}
+	if path == "" {
+		return state, nil
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	err = yaml.Unmarshal(fileBytes, state)
+	if err != nil {
+		return &State{Sort: map[string]columns.Sort{}, path: path}, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if state.Sort == nil {
+		state.Sort = map[string]columns.Sort{}
+	}
+
+	return state, nil
+}
+
+// Save writes the state file, replacing it only once it was written
+// completely.
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	fileBytes, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	err = os.WriteFile(tmp, fileBytes, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"example.com/main/services/utils"
+	"github.com/gdamore/tcell/v2"
+)
+
+// StyleConfig overrides how a health state, sync state or operation phase is
+// drawn. Unset fields keep the default of the state.
+type StyleConfig struct {
+	Fg        string `yaml:"fg"`
+	Bg        string `yaml:"bg"`
+	Bold      *bool  `yaml:"bold"`
+	Underline *bool  `yaml:"underline"`
+}
+
+// defaultStyles derive the styles from the color slots, so they follow the
+// theme. Keys are the lowercase ArgoCD state names, Unknown is shared by the
+// health and the sync state.
+func (c *Config) defaultStyles() map[string]tcell.Style {
+	style := tcell.StyleDefault.Background(c.Background)
+
+	return map[string]tcell.Style{
+		// health
+		"healthy":     style.Foreground(c.Healthy),
+		"progressing": style.Foreground(c.Progressing),
+		"degraded":    style.Foreground(c.Degraded).Bold(true),
+		"missing":     style.Foreground(c.Missing),
+		"suspended":   style.Foreground(c.Header),
+		"unknown":     style.Foreground(c.Header).Underline(true),
+		// sync
+		"synced":    style.Foreground(c.Healthy),
+		"outofsync": style.Foreground(c.Missing).Bold(true),
+		// operation
+		"running":     style.Foreground(c.Progressing),
+		"terminating": style.Foreground(c.Missing),
+		"succeeded":   style.Foreground(c.Healthy),
+		"failed":      style.Foreground(c.Degraded).Bold(true),
+		"error":       style.Foreground(c.Degraded).Bold(true).Underline(true),
+	}
+}
+
+// applyStyles layers the styles section over the default styles. It runs
+// after the colors are set.
+func (c *Config) applyStyles() {
+	c.Styles = c.defaultStyles()
+
+	names := make([]string, 0, len(c.styles))
+	for name := range c.styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		override := c.styles[name]
+		state := strings.ToLower(name)
+
+		style, ok := c.Styles[state]
+		if !ok {
+			c.Problems = append(c.Problems, c.Problem("unknown state", "styles", name))
+			continue
+		}
+
+		if override.Fg != "" {
+			color, err := utils.ParseColor(override.Fg)
+			if err != nil {
+				c.Problems = append(c.Problems, c.Problem(err.Error(), "styles", name, "fg"))
+			} else {
+				style = style.Foreground(color)
+			}
+		}
+
+		if override.Bg != "" {
+			color, err := utils.ParseColor(override.Bg)
+			if err != nil {
+				c.Problems = append(c.Problems, c.Problem(err.Error(), "styles", name, "bg"))
+			} else {
+				style = style.Background(color)
+			}
+		}
+
+		if override.Bold != nil {
+			style = style.Bold(*override.Bold)
+		}
+
+		if override.Underline != nil {
+			style = style.Underline(*override.Underline)
+		}
+
+		c.Styles[state] = style
+	}
+}
+
+// Style returns the style of an ArgoCD health state, sync state or operation
+// phase. States without a style, like resources without health, use the
+// plain foreground color.
+func (c *Config) Style(state string) tcell.Style {
+	if style, ok := c.Styles[strings.ToLower(state)]; ok {
+		return style
+	}
+
+	return tcell.StyleDefault.
+		Foreground(c.Foreground).
+		Background(c.Background)
+}
+
+// StyleTag converts the style of state into a tview color tag, for text
+// views like the status bar.
+func (c *Config) StyleTag(state string) string {
+	fg, bg, attrs := c.Style(state).Decompose()
+
+	flags := "-"
+	if attrs&tcell.AttrBold != 0 {
+		flags = "b"
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		flags = strings.TrimPrefix(flags+"u", "-")
+	}
+
+	return fmt.Sprintf("[%s:%s:%s]", colorTag(fg), colorTag(bg), flags)
+}
+
+func colorTag(color tcell.Color) string {
+	if color == tcell.ColorDefault {
+		return "-"
+	}
+
+	return fmt.Sprintf("#%06x", color.Hex())
+}
@@ -0,0 +1,298 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"example.com/main/services/utils"
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultTheme = "default"
+
+// Colors are the color slots of the UI, used by the colors section of the
+// config file and by themes. Values are hex colors or color names.
+type Colors struct {
+	Text        string `yaml:"text"`
+	Border      string `yaml:"border"`
+	Header      string `yaml:"header"`
+	Foreground  string `yaml:"foreground"`
+	Selected    string `yaml:"selected"`
+	Background  string `yaml:"background"`
+	Progressing string `yaml:"progressing"`
+	Missing     string `yaml:"missing"`
+	Healthy     string `yaml:"healthy"`
+	Degraded    string `yaml:"degraded"`
+}
+
+// Get returns the value of the slot with the given yaml name.
+func (c Colors) Get(slot string) string {
+	field, ok := fieldByTag(reflect.TypeOf(c), slot)
+	if !ok {
+		return ""
+	}
+
+	return reflect.ValueOf(c).FieldByIndex(field.Index).String()
+}
+
+var colorSlots = []string{
+	"background",
+	"text",
+	"border",
+	"header",
+	"foreground",
+	"selected",
+	"progressing",
+	"missing",
+	"healthy",
+	"degraded",
+}
+
+// Themes are the bundled presets. A user theme with the same name replaces
+// the bundled one.
+var Themes = map[string]Colors{
+	DefaultTheme: {
+		Background:  "default",
+		Text:        "white",
+		Border:      "darkslategray",
+		Header:      "gray",
+		Foreground:  "whitesmoke",
+		Selected:    "skyblue",
+		Progressing: "lightblue",
+		Missing:     "lightyellow",
+		Healthy:     "lightgreen",
+		Degraded:    "indianred",
+	},
+	"dracula": {
+		Background:  "#282a36",
+		Text:        "#f8f8f2",
+		Border:      "#6272a4",
+		Header:      "#bd93f9",
+		Foreground:  "#f8f8f2",
+		Selected:    "#ff79c6",
+		Progressing: "#8be9fd",
+		Missing:     "#f1fa8c",
+		Healthy:     "#50fa7b",
+		Degraded:    "#ff5555",
+	},
+	"solarized-dark": {
+		Background:  "#002b36",
+		Text:        "#839496",
+		Border:      "#586e75",
+		Header:      "#268bd2",
+		Foreground:  "#93a1a1",
+		Selected:    "#2aa198",
+		Progressing: "#268bd2",
+		Missing:     "#b58900",
+		Healthy:     "#859900",
+		Degraded:    "#dc322f",
+	},
+	"solarized-light": {
+		Background:  "#fdf6e3",
+		Text:        "#657b83",
+		Border:      "#93a1a1",
+		Header:      "#268bd2",
+		Foreground:  "#586e75",
+		Selected:    "#2aa198",
+		Progressing: "#268bd2",
+		Missing:     "#b58900",
+		Healthy:     "#859900",
+		Degraded:    "#dc322f",
+	},
+	"gruvbox": {
+		Background:  "#282828",
+		Text:        "#ebdbb2",
+		Border:      "#665c54",
+		Header:      "#fabd2f",
+		Foreground:  "#fbf1c7",
+		Selected:    "#83a598",
+		Progressing: "#83a598",
+		Missing:     "#fabd2f",
+		Healthy:     "#b8bb26",
+		Degraded:    "#fb4934",
+	},
+	"catppuccin": {
+		Background:  "#1e1e2e",
+		Text:        "#cdd6f4",
+		Border:      "#585b70",
+		Header:      "#cba6f7",
+		Foreground:  "#cdd6f4",
+		Selected:    "#89b4fa",
+		Progressing: "#89dceb",
+		Missing:     "#f9e2af",
+		Healthy:     "#a6e3a1",
+		Degraded:    "#f38ba8",
+	},
+	"monochrome": {
+		Background:  "#000000",
+		Text:        "#d0d0d0",
+		Border:      "#808080",
+		Header:      "#ffffff",
+		Foreground:  "#d0d0d0",
+		Selected:    "#ffffff",
+		Progressing: "#a8a8a8",
+		Missing:     "#a8a8a8",
+		Healthy:     "#d0d0d0",
+		Degraded:    "#ffffff",
+	},
+	"high-contrast": {
+		Background:  "#000000",
+		Text:        "#ffffff",
+		Border:      "#ffffff",
+		Header:      "#ffff00",
+		Foreground:  "#ffffff",
+		Selected:    "#00ffff",
+		Progressing: "#00afff",
+		Missing:     "#ffff00",
+		Healthy:     "#00ff00",
+		Degraded:    "#ff0000",
+	},
+}
+
+// ThemesDir holds the user themes next to the config file at configPath, one
+// <name>.yaml file per theme with the same keys as the colors section.
+func ThemesDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "themes")
+}
+
+// ThemeNames returns the bundled themes and the user themes in dir, sorted.
+func ThemeNames(dir string) []string {
+	names := []string{}
+	for name := range Themes {
+		names = append(names, name)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		if _, ok := Themes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// LoadTheme returns the colors of the named theme, looking at the user themes
+// in dir first.
+func LoadTheme(dir string, name string) (Colors, error) {
+	colors := Colors{}
+
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return colors, fmt.Errorf("invalid theme name %q", name)
+	}
+
+	path := filepath.Join(dir, name+".yaml")
+
+	fileBytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if colors, ok := Themes[name]; ok {
+			return colors, nil
+		}
+		return colors, fmt.Errorf("unknown theme %q, available themes: %s", name, strings.Join(ThemeNames(dir), ", "))
+	}
+	if err != nil {
+		return colors, err
+	}
+
+	root := yaml.Node{}
+	err = yaml.Unmarshal(fileBytes, &root)
+	if err == nil {
+		err = root.Decode(&colors)
+	}
+	if err != nil {
+		return colors, fmt.Errorf("%s: %v", path, err)
+	}
+
+	problems := unknownKeys(&root, reflect.TypeOf(colors), "")
+	for _, slot := range colorSlots {
+		if value := colors.Get(slot); value != "" {
+			if _, err := utils.ParseColor(value); err != nil {
+				problem := Problem{Path: slot, Message: err.Error()}
+				if node := locate(&root, []string{slot}); node != nil {
+					problem.Line = node.Line
+					problem.Column = node.Column
+				}
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		SortProblems(problems)
+		return colors, fmt.Errorf("%s:%s", path, problems[0])
+	}
+
+	return colors, nil
+}
+
+// applyColors sets every color slot from the colors section, then the theme,
+// then the default theme, and the styles derived from them. Invalid values
+// are reported and fall back.
+func (c *Config) applyColors() {
+	theme := Colors{}
+
+	if c.Theme != "" {
+		var err error
+		theme, err = LoadTheme(ThemesDir(c.Path), c.Theme)
+		if err != nil {
+			c.Problems = append(c.Problems, c.Problem(err.Error(), "theme"))
+			theme = Colors{}
+		}
+	}
+
+	targets := map[string]*tcell.Color{
+		"background":  &c.Background,
+		"text":        &c.Text,
+		"border":      &c.Border,
+		"header":      &c.Header,
+		"foreground":  &c.Foreground,
+		"selected":    &c.Selected,
+		"progressing": &c.Progressing,
+		"missing":     &c.Missing,
+		"healthy":     &c.Healthy,
+		"degraded":    &c.Degraded,
+	}
+
+	for _, slot := range colorSlots {
+		color, _ := utils.ParseColor(Themes[DefaultTheme].Get(slot))
+
+		if value := theme.Get(slot); value != "" {
+			color, _ = utils.ParseColor(value)
+		}
+
+		if value := c.colors.Get(slot); value != "" {
+			parsed, err := utils.ParseColor(value)
+			if err != nil {
+				c.Problems = append(c.Problems, c.Problem(err.Error(), "colors", slot))
+			} else {
+				color = parsed
+			}
+		}
+
+		*targets[slot] = color
+	}
+
+	c.applyStyles()
+}
+
+// WithTheme returns a copy of the config that uses the named theme, for
+// previewing themes. Colors set in the colors section still win.
+func (c *Config) WithTheme(name string) (*Config, error) {
+	if _, err := LoadTheme(ThemesDir(c.Path), name); err != nil {
+		return nil, err
+	}
+
+	themed := *c
+	themed.Theme = name
+	themed.Problems = nil
+	themed.applyColors()
+
+	return &themed, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestThemesNextToConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	err := os.MkdirAll(filepath.Join(dir, "themes"), 0755)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, "themes", "mine.yaml"), []byte("border: red\n"), 0644)
+	}
+	if err == nil {
+		err = os.WriteFile(path, []byte("theme: mine\n"), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Problems) > 0 {
+		t.Fatalf("problems: %v", cfg.Problems)
+	}
+
+	if !slices.Contains(ThemeNames(ThemesDir(path)), "mine") {
+		t.Errorf("ThemeNames(%s) = %v, want mine among them", ThemesDir(path), ThemeNames(ThemesDir(path)))
+	}
+	if _, err := cfg.WithTheme("mine"); err != nil {
+		t.Errorf("WithTheme(mine): %v", err)
+	}
+}
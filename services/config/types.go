@@ -1,22 +1,71 @@
 package config
 
 import (
+	"time"
+
+	"example.com/main/services/argocd"
+	"example.com/main/services/clipboard"
+	"example.com/main/services/columns"
+	"example.com/main/services/keys"
 	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
 )
 
 type InternalConfig struct {
-	Colors struct {
-		Text        string `yaml:"text"`
-		Border      string `yaml:"border"`
-		Header      string `yaml:"header"`
-		Foreground  string `yaml:"foreground"`
-		Selected    string `yaml:"selected"`
-		Background  string `yaml:"background"`
-		Progressing string `yaml:"progressing"`
-		Missing     string `yaml:"missing"`
-		Healthy     string `yaml:"healthy"`
-		Degraded    string `yaml:"degraded"`
-	} `yaml:"colors"`
+	Colors   Colors                       `yaml:"colors"`
+	Theme    string                       `yaml:"theme"`
+	Styles   map[string]StyleConfig       `yaml:"styles"`
+	Keys     map[string]map[string]string `yaml:"keys"`
+	Context  string                       `yaml:"context"`
+	Contexts map[string]ContextConfig     `yaml:"contexts"`
+	Log      struct {
+		Level string `yaml:"level"`
+		File  string `yaml:"file"`
+	} `yaml:"log"`
+	Cache struct {
+		TTL  string `yaml:"ttl"`
+		Size *int   `yaml:"size"`
+	} `yaml:"cache"`
+	Applications struct {
+		Projects     []string `yaml:"projects"`
+		Selector     string   `yaml:"selector"`
+		Repo         string   `yaml:"repo"`
+		AppNamespace string   `yaml:"appNamespace"`
+		PageSize     *int     `yaml:"pageSize"`
+	} `yaml:"applications"`
+	Columns   map[string][]ColumnConfig `yaml:"columns"`
+	Clipboard struct {
+		OSC52   *bool  `yaml:"osc52"`
+		Command string `yaml:"command"`
+	} `yaml:"clipboard"`
+	Editor string `yaml:"editor"`
+}
+
+// ColumnConfig is a column of a view, either just its name or a mapping with
+// its name and width.
+type ColumnConfig struct {
+	Name  string `yaml:"name"`
+	Width int    `yaml:"width"`
+	node  *yaml.Node
+}
+
+func (c *ColumnConfig) UnmarshalYAML(node *yaml.Node) error {
+	c.node = node
+	if node.Kind == yaml.ScalarNode {
+		c.Name = node.Value
+		return nil
+	}
+
+	type plain ColumnConfig
+	return node.Decode((*plain)(c))
+}
+
+// ContextConfig is a named server profile, selected with --context.
+type ContextConfig struct {
+	Server    string `yaml:"server"`
+	Insecure  *bool  `yaml:"insecure"`
+	Readonly  *bool  `yaml:"readonly"`
+	Protected bool   `yaml:"protected"`
 }
 
 type Config struct {
@@ -30,4 +79,23 @@ type Config struct {
 	Missing     tcell.Color
 	Healthy     tcell.Color
 	Degraded    tcell.Color
+	Keys        map[string]map[string]keys.Sequence
+	Path        string
+	Context     string
+	Contexts    map[string]ContextConfig
+	LogLevel    string
+	LogFile     string
+	CacheTTL    time.Duration
+	CacheSize   int
+	ListOptions argocd.ListOptions
+	PageSize    int
+	Columns     map[string][]columns.Column
+	Clipboard   clipboard.Clipboard
+	Editor      string
+	Theme       string
+	Styles      map[string]tcell.Style
+	Problems    []Problem
+	colors      Colors
+	styles      map[string]StyleConfig
+	root        *yaml.Node
 }
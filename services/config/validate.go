@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue found while loading the config file. Line is 0 when the
+// problem cannot be tied to a line, for example a missing value.
+type Problem struct {
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	location := ""
+	if p.Line > 0 {
+		location = fmt.Sprintf("%d:%d: ", p.Line, p.Column)
+	}
+
+	if p.Path == "" {
+		return location + p.Message
+	}
+
+	return fmt.Sprintf("%s%s: %s", location, p.Path, p.Message)
+}
+
+// SortProblems orders problems by their position in the file.
+func SortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+}
+
+// Problem builds a Problem located at the node found by following path
+// through the config file, for example Problem("bad", "colors", "text").
+func (c *Config) Problem(message string, path ...string) Problem {
+	problem := Problem{
+		Path:    strings.Join(path, "."),
+		Message: message,
+	}
+
+	if node := locate(c.root, path); node != nil {
+		problem.Line = node.Line
+		problem.Column = node.Column
+	}
+
+	return problem
+}
+
+// locate follows path through nested mappings. Keys are matched case
+// insensitively, like the contexts of the keys section.
+func locate(node *yaml.Node, path []string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				next = node.Content[i+1]
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		node = next
+	}
+
+	return node
+}
+
+// unknownKeys walks node alongside the type it decodes into and reports every
+// mapping key without a matching yaml tag, which yaml.v3 silently ignores.
+// The items of sequences are checked too, their index is part of the path.
+func unknownKeys(node *yaml.Node, t reflect.Type, path string) []Problem {
+	problems := []Problem{}
+
+	if node == nil {
+		return problems
+	}
+
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			problems = append(problems, unknownKeys(child, t, path)...)
+		}
+		return problems
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if node.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		for i, item := range node.Content {
+			problems = append(problems, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return problems
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		value := node.Content[i+1]
+		childPath := strings.TrimPrefix(path+"."+key.Value, ".")
+
+		switch t.Kind() {
+		case reflect.Map:
+			problems = append(problems, unknownKeys(value, t.Elem(), childPath)...)
+		case reflect.Struct:
+			field, ok := fieldByTag(t, key.Value)
+			if !ok {
+				problems = append(problems, Problem{
+					Line:    key.Line,
+					Column:  key.Column,
+					Path:    childPath,
+					Message: "unknown key",
+				})
+				continue
+			}
+			problems = append(problems, unknownKeys(value, field.Type, childPath)...)
+		}
+	}
+
+	return problems
+}
+
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func validateServerURL(server string) error {
+	u, err := url.Parse(server)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+
+	return nil
+}
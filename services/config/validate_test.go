@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func load(t *testing.T, text string) *Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(strings.TrimLeft(text, "\n")), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLoadValid(t *testing.T) {
+	cfg := load(t, `
+theme: dracula
+colors:
+  border: red
+context: prod
+contexts:
+  prod:
+    server: https://argocd.example.com
+    readonly: true
+log:
+  level: debug
+cache:
+  ttl: 1m
+  size: 10
+applications:
+  projects: [payments]
+  pageSize: 50
+columns:
+  resources:
+    - Name
+    - name: Images
+      width: 40
+clipboard:
+  osc52: false
+editor: vim
+`)
+
+	if len(cfg.Problems) > 0 {
+		t.Errorf("problems: %v", cfg.Problems)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"top level", "colour: red\n", "1:1: colour: unknown key"},
+		{"nested", "log:\n  levle: debug\n", "2:3: log.levle: unknown key"},
+		{"map value", "contexts:\n  prod:\n    sever: https://argocd.example.com\n", "3:5: contexts.prod.sever: unknown key"},
+		{"sequence item", "columns:\n  resources:\n    - Name\n    - name: Images\n      widht: 40\n", "5:7: columns.resources[1].widht: unknown key"},
+		{"flow sequence item", "columns:\n  resources: [Name, {name: Kind, wdth: 5}]\n", "2:34: columns.resources[1].wdth: unknown key"},
+		{"theme colors", "colors:\n  boder: red\n", "2:3: colors.boder: unknown key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := load(t, tt.text)
+
+			found := []string{}
+			for _, problem := range cfg.Problems {
+				found = append(found, problem.String())
+				if problem.String() == tt.want {
+					return
+				}
+			}
+			t.Errorf("problems %q, want %q", found, tt.want)
+		})
+	}
+}
+
+func TestUnknownKeysOnlyOnce(t *testing.T) {
+	cfg := load(t, "columns:\n  resources:\n    - name: Name\n      color: red\n    - name: Kind\n      color: blue\n")
+
+	count := 0
+	for _, problem := range cfg.Problems {
+		if problem.Message == "unknown key" {
+			count++
+		}
+	}
+	if count != 2 {
+		t.Errorf("problems %v, want an unknown key for each item", cfg.Problems)
+	}
+}
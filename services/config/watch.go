@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const DefaultWatchInterval = time.Second
+
+// Watch polls the file at path and calls onChange from its own goroutine
+// whenever the file was modified. Polling also catches editors that replace
+// the file instead of writing it in place. The returned function stops it.
+func Watch(path string, interval time.Duration, onChange func()) func() {
+	stop := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := fingerprint(path)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current := fingerprint(path)
+				if current != "" && current != last {
+					last = current
+					onChange()
+				}
+			}
+		}
+	}()
+
+	return func() { close(stop) }
+}
+
+// fingerprint identifies a version of the file. It is empty while the file
+// is missing, for example while an editor swaps it, which is not a change.
+func fingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
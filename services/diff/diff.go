@@ -0,0 +1,165 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+	// Skip stands for unchanged lines left out by Context, Text tells how
+	// many.
+	Skip
+)
+
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// maxCells bounds the table of the longest common subsequence. Larger
+// changes are shown as the old lines removed and the new ones added.
+const maxCells = 4_000_000
+
+// Lines compares the lines of a and b.
+func Lines(a string, b string) []Line {
+	before := split(a)
+	after := split(b)
+
+	// the common start and end are cut off, edits usually change a few lines
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	lines := []Line{}
+	for _, text := range before[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	lines = append(lines, middle(before[prefix:len(before)-suffix], after[prefix:len(after)-suffix])...)
+	for _, text := range before[len(before)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+
+	return lines
+}
+
+func middle(before []string, after []string) []Line {
+	lines := []Line{}
+	if (len(before)+1)*(len(after)+1) > maxCells {
+		for _, text := range before {
+			lines = append(lines, Line{Op: Delete, Text: text})
+		}
+		for _, text := range after {
+			lines = append(lines, Line{Op: Insert, Text: text})
+		}
+		return lines
+	}
+
+	// common[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			lines = append(lines, Line{Op: Equal, Text: before[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: before[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		lines = append(lines, Line{Op: Delete, Text: before[i]})
+	}
+	for ; j < len(after); j++ {
+		lines = append(lines, Line{Op: Insert, Text: after[j]})
+	}
+
+	return lines
+}
+
+// Changed reports whether lines has other lines than equal ones.
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Context keeps n unchanged lines around each change and replaces the
+// others with a Skip line.
+func Context(lines []Line, n int) []Line {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Op == Equal {
+			continue
+		}
+		for k := max(0, i-n); k <= min(len(lines)-1, i+n); k++ {
+			keep[k] = true
+		}
+	}
+
+	result := []Line{}
+	skipped := 0
+	for i, line := range lines {
+		if keep[i] {
+			if skipped > 0 {
+				result = append(result, skipLine(skipped))
+				skipped = 0
+			}
+			result = append(result, line)
+			continue
+		}
+		skipped++
+	}
+	if skipped > 0 {
+		result = append(result, skipLine(skipped))
+	}
+
+	return result
+}
+
+func skipLine(n int) Line {
+	if n == 1 {
+		return Line{Op: Skip, Text: "1 unchanged line"}
+	}
+	return Line{Op: Skip, Text: strconv.Itoa(n) + " unchanged lines"}
+}
+
+func split(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// render writes lines like a unified diff, with ~ before skipped lines.
+func render(lines []Line) string {
+	b := &strings.Builder{}
+	for _, line := range lines {
+		b.WriteString([]string{" ", "-", "+", "~"}[line.Op] + line.Text + "\n")
+	}
+	return b.String()
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", " a\n b\n"},
+		{"both empty", "", "", ""},
+		{"add", "a\nc\n", "a\nb\nc\n", " a\n+b\n c\n"},
+		{"add at the end", "a\n", "a\nb\n", " a\n+b\n"},
+		{"add to empty", "", "a\n", "+a\n"},
+		{"remove", "a\nb\nc\n", "a\nc\n", " a\n-b\n c\n"},
+		{"remove all", "a\nb\n", "", "-a\n-b\n"},
+		{"change", "a\nb\nc\n", "a\nB\nc\n", " a\n-b\n+B\n c\n"},
+		{"change and move", "a\nb\nc\nd\n", "b\nc\na\nd\n", "-a\n b\n c\n+a\n d\n"},
+		{"missing final newline", "a\nb", "a\nb\n", " a\n b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.before, tt.after)
+			if got := render(lines); got != tt.want {
+				t.Errorf("Lines =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			// only equal lines render without a leading - or +
+			want := strings.Contains("\n"+tt.want, "\n-") || strings.Contains("\n"+tt.want, "\n+")
+			if got := Changed(lines); got != want {
+				t.Errorf("Changed = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	before := strings.Repeat("a\n", 2500)
+	after := strings.Repeat("b\n", 2500)
+
+	lines := Lines("x\n"+before+"y\n", "x\n"+after+"y\n")
+	if len(lines) != 5002 || lines[0].Op != Equal || lines[1].Op != Delete || lines[2501].Op != Insert || lines[5001].Op != Equal {
+		t.Errorf("Lines of a large change gave %d lines, want the old lines removed and the new ones added", len(lines))
+	}
+}
+
+func TestContext(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	after := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "~4 unchanged lines\n-5\n+five\n~4 unchanged lines\n"},
+		{1, "~3 unchanged lines\n 4\n-5\n+five\n 6\n~3 unchanged lines\n"},
+		{3, "~1 unchanged line\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n~1 unchanged line\n"},
+		{4, " 1\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n"},
+	}
+
+	for _, tt := range tests {
+		if got := render(Context(Lines(before, after), tt.n)); got != tt.want {
+			t.Errorf("Context(%d) =\n%s\nwant\n%s", tt.n, got, tt.want)
+		}
+	}
+
+	if got := render(Context(Lines(before, before), 3)); got != "~9 unchanged lines\n" {
+		t.Errorf("Context without changes = %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package images
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/main/services/argocd"
+)
+
+// Reference is an image reference split into the image and its version, the
+// tag or digest. A reference without either runs latest.
+type Reference struct {
+	Image   string
+	Version string
+}
+
+// Parse splits ref like registry:5000/team/app:1.2@sha256:… into the image
+// and the version. A digest wins over the tag, it is what actually runs.
+func Parse(ref string) Reference {
+	if image, digest, ok := strings.Cut(ref, "@"); ok {
+		image, _, _ = cutTag(image)
+		return Reference{Image: image, Version: digest}
+	}
+
+	if image, tag, ok := cutTag(ref); ok {
+		return Reference{Image: image, Version: tag}
+	}
+
+	return Reference{Image: ref, Version: "latest"}
+}
+
+// cutTag cuts the tag off ref. A colon before the last slash belongs to the
+// registry port.
+func cutTag(ref string) (string, string, bool) {
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || i < strings.LastIndex(ref, "/") {
+		return ref, "", false
+	}
+	return ref[:i], ref[i+1:], true
+}
+
+// Usage is a version of an image and where it runs. Drift is set when other
+// versions of the image run as well.
+type Usage struct {
+	Image        string   `json:"image"`
+	Version      string   `json:"version"`
+	Applications []string `json:"applications"`
+	Resources    int      `json:"resources"`
+	Drift        bool     `json:"drift"`
+}
+
+// Report aggregates the images of the resources in trees, keyed by
+// application name, ordered by image and version.
+func Report(trees map[string][]argocd.ApplicationNode) []Usage {
+	usages := map[Reference]*Usage{}
+	versions := map[string]int{}
+
+	for app, nodes := range trees {
+		for _, node := range nodes {
+			for _, ref := range node.Images {
+				reference := Parse(ref)
+
+				usage, ok := usages[reference]
+				if !ok {
+					usage = &Usage{Image: reference.Image, Version: reference.Version}
+					usages[reference] = usage
+					versions[reference.Image]++
+				}
+
+				usage.Resources++
+				if !slices.Contains(usage.Applications, app) {
+					usage.Applications = append(usage.Applications, app)
+				}
+			}
+		}
+	}
+
+	report := make([]Usage, 0, len(usages))
+	for _, usage := range usages {
+		sort.Strings(usage.Applications)
+		usage.Drift = versions[usage.Image] > 1
+		report = append(report, *usage)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Image != report[j].Image {
+			return report[i].Image < report[j].Image
+		}
+		return report[i].Version < report[j].Version
+	})
+
+	return report
+}
+
+// Drifting counts the images running in more than one version.
+func Drifting(report []Usage) int {
+	images := map[string]bool{}
+	for _, usage := range report {
+		if usage.Drift {
+			images[usage.Image] = true
+		}
+	}
+	return len(images)
+}
+
+// Columns are the header of the report as a table, Row a line of it.
+var Columns = []string{"IMAGE", "VERSION", "APPS", "RESOURCES", "DRIFT", "APPLICATIONS"}
+
+func Row(usage Usage) []string {
+	drift := ""
+	if usage.Drift {
+		drift = "yes"
+	}
+
+	return []string{
+		usage.Image,
+		usage.Version,
+		strconv.Itoa(len(usage.Applications)),
+		strconv.Itoa(usage.Resources),
+		drift,
+		strings.Join(usage.Applications, " "),
+	}
+}
+
+// WriteCSV writes the report as CSV with a header line.
+func WriteCSV(w io.Writer, report []Usage) error {
+	writer := csv.NewWriter(w)
+	writer.Write(Columns)
+	for _, usage := range report {
+		writer.Write(Row(usage))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the report as an indented JSON array.
+func WriteJSON(w io.Writer, report []Usage) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
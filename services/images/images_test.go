@@ -0,0 +1,88 @@
+package images
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	"example.com/main/services/argocd"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want Reference
+	}{
+		{"nginx", Reference{"nginx", "latest"}},
+		{"nginx:1.25", Reference{"nginx", "1.25"}},
+		{"library/nginx:1.25-alpine", Reference{"library/nginx", "1.25-alpine"}},
+		{"registry:5000/team/app", Reference{"registry:5000/team/app", "latest"}},
+		{"registry:5000/team/app:1.2", Reference{"registry:5000/team/app", "1.2"}},
+		{"app@sha256:abc", Reference{"app", "sha256:abc"}},
+		{"app:1.2@sha256:abc", Reference{"app", "sha256:abc"}},
+		{"registry:5000/team/app:1.2@sha256:abc", Reference{"registry:5000/team/app", "sha256:abc"}},
+		{"registry:5000/team/app@sha256:abc", Reference{"registry:5000/team/app", "sha256:abc"}},
+	}
+
+	for _, tt := range tests {
+		if got := Parse(tt.ref); got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func node(images ...string) argocd.ApplicationNode {
+	return argocd.ApplicationNode{Images: images}
+}
+
+func TestReport(t *testing.T) {
+	report := Report(map[string][]argocd.ApplicationNode{
+		"payments": {node("nginx:1.25", "redis:7"), node("nginx:1.25")},
+		"checkout": {node("nginx:1.24"), node()},
+		"billing":  {node("nginx:1.25", "registry:5000/team/app")},
+	})
+
+	want := []Usage{
+		{Image: "nginx", Version: "1.24", Applications: []string{"checkout"}, Resources: 1, Drift: true},
+		{Image: "nginx", Version: "1.25", Applications: []string{"billing", "payments"}, Resources: 3, Drift: true},
+		{Image: "redis", Version: "7", Applications: []string{"payments"}, Resources: 1},
+		{Image: "registry:5000/team/app", Version: "latest", Applications: []string{"billing"}, Resources: 1},
+	}
+
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Report =\n%+v\nwant\n%+v", report, want)
+	}
+
+	if got := Drifting(report); got != 1 {
+		t.Errorf("Drifting = %d, want 1", got)
+	}
+
+	if got, want := Row(report[1]), []string{"nginx", "1.25", "2", "3", "yes", "billing payments"}; !slices.Equal(got, want) {
+		t.Errorf("Row = %q, want %q", got, want)
+	}
+}
+
+func TestReportEmpty(t *testing.T) {
+	report := Report(map[string][]argocd.ApplicationNode{"empty": {node()}})
+	if len(report) != 0 || Drifting(report) != 0 {
+		t.Errorf("Report of no images = %+v", report)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report := []Usage{
+		{Image: "nginx", Version: "1.25", Applications: []string{"a", "b"}, Resources: 2, Drift: true},
+		{Image: "redis", Version: "7", Applications: []string{"a"}, Resources: 1},
+	}
+
+	var b bytes.Buffer
+	if err := WriteCSV(&b, report); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "IMAGE,VERSION,APPS,RESOURCES,DRIFT,APPLICATIONS\nnginx,1.25,2,2,yes,a b\nredis,7,1,1,,a\n"
+	if b.String() != want {
+		t.Errorf("WriteCSV =\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,262 @@
+package keys
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+type Stroke struct {
+	Key  tcell.Key
+	Rune rune
+	Mod  tcell.ModMask
+}
+
+type Sequence []Stroke
+
+var namedKeys = map[string]tcell.Key{}
+
+func init() {
+	for key, name := range tcell.KeyNames {
+		if strings.HasPrefix(name, "Ctrl-") {
+			continue
+		}
+		namedKeys[strings.ToLower(name)] = key
+	}
+
+	namedKeys["escape"] = tcell.KeyEsc
+	namedKeys["return"] = tcell.KeyEnter
+	namedKeys["del"] = tcell.KeyDelete
+	namedKeys["ins"] = tcell.KeyInsert
+	namedKeys["pagedown"] = tcell.KeyPgDn
+	namedKeys["pageup"] = tcell.KeyPgUp
+	namedKeys["pgdown"] = tcell.KeyPgDn
+	delete(namedKeys, "backspace2")
+}
+
+// FromEvent normalizes a tcell key event so that it compares equal to the
+// Stroke produced by Parse for the same key spec.
+func FromEvent(event *tcell.EventKey) Stroke {
+	mod := event.Modifiers()
+
+	switch key := event.Key(); {
+	case key == tcell.KeyRune:
+		// shift is already encoded in the case of the rune
+		return Stroke{Key: tcell.KeyRune, Rune: event.Rune(), Mod: mod & (tcell.ModAlt | tcell.ModCtrl)}
+	case key == tcell.KeyBackspace2:
+		return Stroke{Key: tcell.KeyBackspace, Mod: mod &^ tcell.ModCtrl}
+	case key == tcell.KeyBacktab:
+		// shift is already encoded in the key, like Parse does for shift+tab
+		return Stroke{Key: key, Mod: mod &^ tcell.ModShift}
+	case key < tcell.KeyRune:
+		// ctrl is already encoded in the control key itself
+		return Stroke{Key: key, Mod: mod &^ (tcell.ModCtrl | tcell.ModShift)}
+	default:
+		return Stroke{Key: key, Mod: mod}
+	}
+}
+
+// Parse reads a whitespace separated sequence of key specs such as
+// "ctrl+r", "shift+j", "F5" or "g g".
+func Parse(spec string) (Sequence, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty key spec")
+	}
+
+	seq := Sequence{}
+	for _, field := range fields {
+		stroke, err := ParseStroke(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key spec %q: %w", spec, err)
+		}
+		seq = append(seq, stroke)
+	}
+
+	return seq, nil
+}
+
+func MustParse(spec string) Sequence {
+	seq, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return seq
+}
+
+func ParseStroke(spec string) (Stroke, error) {
+	parts := strings.Split(spec, "+")
+	if strings.HasSuffix(spec, "+") {
+		// "+" and "ctrl++" bind the plus key itself
+		parts = strings.Split(strings.TrimSuffix(spec, "+"), "+")
+		parts[len(parts)-1] = "+"
+	}
+
+	name := parts[len(parts)-1]
+	var mod tcell.ModMask
+
+	for _, modifier := range parts[:len(parts)-1] {
+		switch strings.ToLower(modifier) {
+		case "ctrl", "control":
+			mod |= tcell.ModCtrl
+		case "alt", "meta":
+			mod |= tcell.ModAlt
+		case "shift":
+			mod |= tcell.ModShift
+		default:
+			return Stroke{}, fmt.Errorf("unknown modifier %q", modifier)
+		}
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		return runeStroke([]rune(name)[0], mod)
+	}
+
+	if strings.ToLower(name) == "space" {
+		return runeStroke(' ', mod)
+	}
+
+	key, ok := namedKeys[strings.ToLower(name)]
+	if !ok {
+		return Stroke{}, fmt.Errorf("unknown key %q", name)
+	}
+
+	if key == tcell.KeyTab && mod&tcell.ModShift != 0 {
+		return Stroke{Key: tcell.KeyBacktab, Mod: mod &^ tcell.ModShift}, nil
+	}
+
+	return Stroke{Key: key, Mod: mod}, nil
+}
+
+func runeStroke(r rune, mod tcell.ModMask) (Stroke, error) {
+	if mod&tcell.ModShift != 0 {
+		r = unicode.ToUpper(r)
+		mod &^= tcell.ModShift
+	}
+
+	if mod&tcell.ModCtrl != 0 {
+		lower := unicode.ToLower(r)
+		switch {
+		case lower >= 'a' && lower <= 'z':
+			return Stroke{Key: tcell.KeyCtrlA + tcell.Key(lower-'a'), Mod: mod &^ tcell.ModCtrl}, nil
+		case r == ' ':
+			return Stroke{Key: tcell.KeyCtrlSpace, Mod: mod &^ tcell.ModCtrl}, nil
+		default:
+			return Stroke{}, fmt.Errorf("ctrl cannot be combined with %q", r)
+		}
+	}
+
+	return Stroke{Key: tcell.KeyRune, Rune: r, Mod: mod}, nil
+}
+
+// String returns the canonical spec for the stroke, which Parse accepts.
+func (s Stroke) String() string {
+	mods := []string{}
+	if s.Mod&tcell.ModCtrl != 0 {
+		mods = append(mods, "ctrl")
+	}
+	if s.Mod&tcell.ModAlt != 0 {
+		mods = append(mods, "alt")
+	}
+	if s.Mod&tcell.ModShift != 0 {
+		mods = append(mods, "shift")
+	}
+
+	name := ""
+	switch {
+	case s.Key == tcell.KeyRune && s.Rune == ' ':
+		name = "space"
+	case s.Key == tcell.KeyRune:
+		name = string(s.Rune)
+	case s.Key == tcell.KeyCtrlSpace:
+		mods = append([]string{"ctrl"}, mods...)
+		name = "space"
+	case s.Key >= tcell.KeyCtrlA && s.Key <= tcell.KeyCtrlZ && strings.HasPrefix(tcell.KeyNames[s.Key], "Ctrl-"):
+		mods = append([]string{"ctrl"}, mods...)
+		name = string(rune('a' + s.Key - tcell.KeyCtrlA))
+	default:
+		name = strings.ToLower(tcell.KeyNames[s.Key])
+		if name == "" {
+			name = fmt.Sprintf("key%d", s.Key)
+		}
+	}
+
+	return strings.Join(append(mods, name), "+")
+}
+
+func (s Sequence) String() string {
+	parts := make([]string, len(s))
+	for i, stroke := range s {
+		parts[i] = stroke.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func (s Sequence) HasPrefix(prefix Sequence) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Display renders the stroke the way vim documents keys, for example "j",
+// "<Esc>", "<C-r>" or "<M-x>".
+func (s Stroke) Display() string {
+	mods := ""
+	if s.Mod&tcell.ModCtrl != 0 {
+		mods += "C-"
+	}
+	if s.Mod&tcell.ModAlt != 0 {
+		mods += "M-"
+	}
+	if s.Mod&tcell.ModShift != 0 {
+		mods += "S-"
+	}
+
+	name := ""
+	switch {
+	case s.Key == tcell.KeyRune && s.Rune == ' ':
+		name = "Space"
+	case s.Key == tcell.KeyRune && s.Rune == '<':
+		name = "lt"
+	case s.Key == tcell.KeyRune:
+		if mods == "" {
+			return string(s.Rune)
+		}
+		name = string(s.Rune)
+	case s.Key == tcell.KeyCtrlSpace:
+		mods = "C-" + mods
+		name = "Space"
+	case s.Key >= tcell.KeyCtrlA && s.Key <= tcell.KeyCtrlZ && strings.HasPrefix(tcell.KeyNames[s.Key], "Ctrl-"):
+		mods = "C-" + mods
+		name = string(rune('a' + s.Key - tcell.KeyCtrlA))
+	case s.Key == tcell.KeyBacktab:
+		mods = "S-" + mods
+		name = "Tab"
+	default:
+		name = tcell.KeyNames[s.Key]
+		if name == "" {
+			name = fmt.Sprintf("Key%d", s.Key)
+		}
+	}
+
+	return fmt.Sprintf("<%s%s>", mods, name)
+}
+
+func (s Sequence) Display() string {
+	display := ""
+	for _, stroke := range s {
+		display += stroke.Display()
+	}
+	return display
+}
@@ -0,0 +1,130 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestModifierNormalization(t *testing.T) {
+	tests := []struct {
+		spec  string
+		event *tcell.EventKey
+	}{
+		{"j", tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone)},
+		{"shift+j", tcell.NewEventKey(tcell.KeyRune, 'J', tcell.ModShift)},
+		{"J", tcell.NewEventKey(tcell.KeyRune, 'J', tcell.ModNone)},
+		{"alt+o", tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModAlt)},
+		{"ctrl+t", tcell.NewEventKey(tcell.KeyCtrlT, 0, tcell.ModCtrl)},
+		{"control+T", tcell.NewEventKey(tcell.KeyCtrlT, 0, tcell.ModNone)},
+		{"ctrl+space", tcell.NewEventKey(tcell.KeyCtrlSpace, 0, tcell.ModCtrl)},
+		{"shift+tab", tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModShift)},
+		{"backspace", tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone)},
+		{"esc", tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)},
+		{"F5", tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone)},
+		{"alt+F5", tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModAlt)},
+		{"space", tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)},
+	}
+
+	for _, tt := range tests {
+		seq, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+
+		if got := FromEvent(tt.event); len(seq) != 1 || got != seq[0] {
+			t.Errorf("FromEvent(%v) = %v, want %v from Parse(%q)", tt.event.Name(), got, seq, tt.spec)
+		}
+	}
+}
+
+func TestParseRejectsInvalidModifiers(t *testing.T) {
+	for _, spec := range []string{"", "hyper+j", "ctrl+1", "ctrl++", "nokey"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"j", "j"},
+		{"shift+j", "J"},
+		{"ctrl+r", "ctrl+r"},
+		{"Control+R", "ctrl+r"},
+		{"alt+o", "alt+o"},
+		{"meta+o", "alt+o"},
+		{"ctrl+alt+x", "ctrl+alt+x"},
+		{"ctrl+space", "ctrl+space"},
+		{"space", "space"},
+		{"shift+tab", "backtab"},
+		{"Escape", "esc"},
+		{"return", "enter"},
+		{"pgdown", "pgdn"},
+		{"alt+F5", "alt+f5"},
+		{"+", "+"},
+		{"alt++", "alt++"},
+		{"g g", "g g"},
+	}
+
+	for _, tt := range tests {
+		seq, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.spec, err)
+			continue
+		}
+
+		if got := seq.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.spec, got, tt.want)
+		}
+
+		again, err := Parse(seq.String())
+		if err != nil || again.String() != seq.String() {
+			t.Errorf("Parse(%q) = %v, %v, want it to read back as %v", seq.String(), again, err, seq)
+		}
+	}
+}
+
+func TestParseStroke(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Stroke
+	}{
+		{"a", Stroke{Key: tcell.KeyRune, Rune: 'a'}},
+		{"shift+a", Stroke{Key: tcell.KeyRune, Rune: 'A'}},
+		{"ctrl+a", Stroke{Key: tcell.KeyCtrlA}},
+		{"ctrl+shift+a", Stroke{Key: tcell.KeyCtrlA}},
+		{"alt+enter", Stroke{Key: tcell.KeyEnter, Mod: tcell.ModAlt}},
+		{"shift+tab", Stroke{Key: tcell.KeyBacktab}},
+		{"del", Stroke{Key: tcell.KeyDelete}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStroke(tt.spec)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseStroke(%q) = %v, %v, want %v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := map[string]string{
+		"j":         "j",
+		"esc":       "<Esc>",
+		"ctrl+r":    "<C-r>",
+		"alt+x":     "<M-x>",
+		"shift+tab": "<S-Tab>",
+		"<":         "<lt>",
+		"g g":       "gg",
+	}
+
+	for spec, want := range tests {
+		if got := MustParse(spec).Display(); got != want {
+			t.Errorf("Display of %q = %q, want %q", spec, got, want)
+		}
+	}
+}
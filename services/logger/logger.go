@@ -1,19 +1,25 @@
 package logger
 
 import (
-	"github.com/sirupsen/logrus"
 	"os"
-)
 
-const debugFileName = "debug.json"
+	"github.com/sirupsen/logrus"
+)
 
-func SetupLogger() *logrus.Logger {
+func SetupLogger(level string, path string) *logrus.Logger {
 	var logger = logrus.New()
 
 	logger.SetFormatter(&logrus.JSONFormatter{})
-	logger.SetLevel(logrus.DebugLevel)
 
-	logFile, err := os.OpenFile(debugFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		logger.SetOutput(os.Stderr)
+		logger.Fatalf("Invalid log level %q: %v", level, err)
+	}
+
+	logger.SetLevel(logLevel)
+
+	logFile, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		logger.SetOutput(os.Stderr)
 		logger.Fatalf("Error opening file: %v", err)
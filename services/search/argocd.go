@@ -0,0 +1,105 @@
+package search
+
+import (
+	"slices"
+	"sort"
+
+	"example.com/main/services/argocd"
+)
+
+// AppFields are the qualifiers known for applications, ResourceFields for
+// the resources of an application.
+var (
+	AppFields      = []string{"name", "health", "sync", "ns", "project", "label", "image"}
+	ResourceFields = []string{"name", "kind", "health", "ns", "label", "image", "info"}
+	GlobalFields   = append(slices.Clone(ResourceFields), "app")
+)
+
+// Application describes app for queries. Terms without a qualifier match
+// the name.
+func Application(app argocd.ApplicationItem) Subject {
+	return func(field string) []string {
+		switch field {
+		case "", "name":
+			return []string{app.Metadata.Name}
+		case "health":
+			return []string{string(app.Status.Health.Status)}
+		case "sync":
+			return []string{string(app.Status.Sync.Status)}
+		case "ns":
+			return []string{app.DestinationNamespace()}
+		case "project":
+			return []string{app.Project()}
+		case "label":
+			return labels(app.Metadata.Labels)
+		case "image":
+			return app.Status.Summary.Images
+		}
+		return nil
+	}
+}
+
+// Resource describes a node of a resource tree for queries. Terms without a
+// qualifier match any of its fields.
+func Resource(node argocd.ApplicationNode) Subject {
+	var subject Subject
+	subject = func(field string) []string {
+		switch field {
+		case "":
+			values := []string{}
+			for _, field := range ResourceFields {
+				values = append(values, subject(field)...)
+			}
+			return values
+		case "name":
+			return []string{node.Name}
+		case "kind":
+			return []string{node.Kind}
+		case "health":
+			return []string{node.Health.Status}
+		case "ns":
+			return []string{node.Namespace}
+		case "label":
+			return labels(node.NetworkingInfo.Labels)
+		case "image":
+			return node.Images
+		case "info":
+			return Info(node)
+		}
+		return nil
+	}
+	return subject
+}
+
+// GlobalResource describes a node of the resource tree of app for the
+// global search, where app: qualifies the application.
+func GlobalResource(app string, node argocd.ApplicationNode) Subject {
+	resource := Resource(node)
+	return func(field string) []string {
+		switch field {
+		case "":
+			return append(resource(field), app)
+		case "app":
+			return []string{app}
+		}
+		return resource(field)
+	}
+}
+
+// Info formats the info items of node, like "Status Reason: Running".
+func Info(node argocd.ApplicationNode) []string {
+	values := make([]string, len(node.Info))
+	for i, item := range node.Info {
+		values[i] = item.Name + ": " + item.Value
+	}
+	return values
+}
+
+func labels(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for key, value := range m {
+		values = append(values, key+"="+value)
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 8
+	bonusBoundary    = 10
+	penaltyGap       = 1
+)
+
+// fuzzy matches the runes of pattern in order anywhere in text, ignoring
+// case unless sensitive is set. Of the matches ending first, it keeps the shortest one, so "dep"
+// in "my-deployment" matches "dep" rather than "d..e..p". It returns the
+// rune indexes of the matched characters and a score that favors
+// consecutive characters and characters at word boundaries.
+func fuzzy(pattern string, text string, sensitive bool) (int, []int, bool) {
+	p := fold(pattern, sensitive)
+	t := fold(text, sensitive)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	// forward to the end of the first match
+	end := -1
+	for i, j := 0, 0; i < len(t); i++ {
+		if t[i] == p[j] {
+			j++
+			if j == len(p) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// backward from there to the closest start
+	positions := make([]int, len(p))
+	for i, j := end, len(p)-1; j >= 0; i-- {
+		if t[i] == p[j] {
+			positions[j] = i
+			j--
+		}
+	}
+
+	runes := []rune(text)
+	score := 0
+	for k, i := range positions {
+		score += scoreMatch
+		if k > 0 && positions[k-1] == i-1 {
+			score += bonusConsecutive
+		}
+		if boundary(runes, i) {
+			score += bonusBoundary
+		}
+	}
+	score -= penaltyGap * (positions[len(positions)-1] - positions[0] + 1 - len(positions))
+
+	return score, positions, true
+}
+
+// substring matches pattern in text, ignoring case unless sensitive is set,
+// scored like a fuzzy match of consecutive characters.
+func substring(pattern string, text string, sensitive bool) (int, []int, bool) {
+	p := fold(pattern, sensitive)
+	t := fold(text, sensitive)
+
+	for i := 0; i+len(p) <= len(t); i++ {
+		if string(t[i:i+len(p)]) != string(p) {
+			continue
+		}
+
+		positions := make([]int, len(p))
+		score := (scoreMatch+bonusConsecutive)*len(p) - bonusConsecutive
+		for k := range p {
+			positions[k] = i + k
+		}
+		if boundary([]rune(text), i) {
+			score += bonusBoundary
+		}
+		return score, positions, true
+	}
+
+	return 0, nil, false
+}
+
+// boundary reports whether the rune at i starts a word, like the d in
+// my-deployment or the D in myDeployment.
+func boundary(t []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev, cur := t[i-1], t[i]
+	switch {
+	case strings.ContainsRune("-_./: ", prev):
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return true
+	}
+
+	return false
+}
+
+// fold lowercases text rune by rune unless sensitive is set, so indexes
+// into the result are indexes into text either way.
+func fold(text string, sensitive bool) []rune {
+	runes := []rune(text)
+	if sensitive {
+		return runes
+	}
+
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
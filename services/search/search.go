@@ -0,0 +1,391 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Subject returns the values of field for the item being searched. The field
+// is empty for terms without a qualifier.
+type Subject func(field string) []string
+
+// Term is a single condition of a query, like health:Degraded or !nginx.
+// Values with an uppercase letter match case sensitively.
+type Term struct {
+	Field     string
+	Value     string
+	Negate    bool
+	Sensitive bool
+	re        *regexp.Regexp
+}
+
+// Mode is how the terms of a query match.
+type Mode string
+
+const (
+	ModeSubstring Mode = ""
+	ModeFuzzy     Mode = "fuzzy"
+	ModeRegex     Mode = "regex"
+)
+
+// Query is a parsed search. Terms next to each other must all match, OR
+// separates alternatives: "health:Degraded ns:prod OR sync:OutOfSync". A
+// leading ~ matches unqualified terms fuzzily, a leading re: matches every
+// term as a regular expression. The zero Query matches everything.
+type Query struct {
+	Text string
+	Mode Mode
+	// any of the groups has to match, every term of a group
+	groups [][]Term
+}
+
+// Error is an invalid query, Start and End are the byte offsets of the
+// offending token in the query text.
+type Error struct {
+	Token   string
+	Start   int
+	End     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %q", e.Message, e.Token)
+}
+
+type token struct {
+	text  string
+	start int
+}
+
+// Parse reads a query whose terms may be qualified by fields. A term whose
+// prefix is not one of fields is searched as it is, colon included.
+func Parse(text string, fields ...string) (Query, error) {
+	query := Query{Text: text}
+	group := []Term{}
+
+	offset := 0
+	if rest, ok := strings.CutPrefix(text, "~"); ok {
+		query.Mode = ModeFuzzy
+		offset = len(text) - len(rest)
+		text = rest
+	} else if rest, ok := strings.CutPrefix(text, "re:"); ok {
+		query.Mode = ModeRegex
+		offset = len(text) - len(rest)
+		text = rest
+	}
+
+	tokens := tokenize(text, offset)
+	for i, tok := range tokens {
+		switch tok.text {
+		case "OR", "||":
+			if len(group) == 0 || i == len(tokens)-1 {
+				return Query{}, tok.error("OR needs a term on both sides")
+			}
+			query.groups = append(query.groups, group)
+			group = []Term{}
+			continue
+		case "AND", "&&":
+			if len(group) == 0 || i == len(tokens)-1 || isOperator(tokens[i+1].text) {
+				return Query{}, tok.error("AND needs a term on both sides")
+			}
+			continue
+		}
+
+		term, err := parseTerm(tok, query.Mode, fields)
+		if err != nil {
+			return Query{}, err
+		}
+		group = append(group, term)
+	}
+
+	if len(group) > 0 {
+		query.groups = append(query.groups, group)
+	}
+
+	return query, nil
+}
+
+func isOperator(text string) bool {
+	return text == "OR" || text == "||" || text == "AND" || text == "&&"
+}
+
+// tokenize splits text at whitespace. The start of each token is offset by
+// offset, so it points into the query text text was cut from.
+func tokenize(text string, offset int) []token {
+	tokens := []token{}
+	start := -1
+
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, token{text: text[start:i], start: start + offset})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{text: text[start:], start: start + offset})
+	}
+
+	return tokens
+}
+
+func (t token) error(message string) *Error {
+	return &Error{
+		Token:   t.text,
+		Start:   t.start,
+		End:     t.start + len(t.text),
+		Message: message,
+	}
+}
+
+func parseTerm(tok token, mode Mode, fields []string) (Term, error) {
+	term := Term{Value: tok.text}
+
+	if rest, ok := strings.CutPrefix(term.Value, "!"); ok {
+		term.Negate = true
+		term.Value = rest
+	}
+
+	// only a known field qualifies a term, nginx:1.25 or :8080 are text
+	if field, value, ok := strings.Cut(term.Value, ":"); ok && slices.Contains(fields, strings.ToLower(field)) {
+		field = strings.ToLower(field)
+		term.Field = field
+		term.Value = value
+
+		if field == "label" {
+			if key, _, _ := strings.Cut(value, "="); key == "" && value != "" {
+				return Term{}, tok.error("expected label:key or label:key=value")
+			}
+		}
+	}
+
+	if term.Value == "" {
+		return Term{}, tok.error("missing value")
+	}
+
+	term.Sensitive = hasUpper(term.Value, mode == ModeRegex)
+
+	if mode == ModeRegex {
+		pattern := term.Value
+		if !term.Sensitive {
+			pattern = "(?i)" + pattern
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			message := err.Error()
+			var syntaxErr *syntax.Error
+			if errors.As(err, &syntaxErr) {
+				message = syntaxErr.Code.String()
+			}
+			return Term{}, tok.error(message)
+		}
+		term.re = re
+	}
+
+	return term, nil
+}
+
+// hasUpper reports whether value has an uppercase letter, which makes the
+// term case sensitive. Escapes like \S in a regular expression don't count.
+func hasUpper(value string, regex bool) bool {
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case regex && r == '\\':
+			escaped = true
+		case unicode.IsUpper(r):
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match reports whether the item described by subject matches the query.
+func (q Query) Match(subject Subject) bool {
+	_, ok := q.Score(subject)
+	return ok
+}
+
+// Score tells whether the item described by subject matches the query and
+// how well, for ranking fuzzy results. The best matching alternative counts.
+func (q Query) Score(subject Subject) (int, bool) {
+	if len(q.groups) == 0 {
+		return 0, true
+	}
+
+	best, matched := 0, false
+	for _, group := range q.groups {
+		score, ok := q.scoreGroup(group, subject)
+		if ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+
+	return best, matched
+}
+
+func (q Query) scoreGroup(group []Term, subject Subject) (int, bool) {
+	total := 0
+
+	for _, term := range group {
+		score, _, ok := q.match(term, subject(term.Field))
+		if ok == term.Negate {
+			return 0, false
+		}
+		total += score
+	}
+
+	return total, true
+}
+
+// match returns the score of the best matching value and the rune indexes
+// of its matched characters.
+func (q Query) match(t Term, values []string) (int, []int, bool) {
+	best, positions, matched := 0, []int(nil), false
+
+	for _, value := range values {
+		if t.Field == "label" && t.re == nil {
+			// label:team matches the key, label:team=core the key and value
+			key, _, _ := strings.Cut(value, "=")
+			if t.equal(value) || t.equal(key) {
+				return 0, nil, true
+			}
+			continue
+		}
+
+		var (
+			score int
+			pos   []int
+			ok    bool
+		)
+		switch {
+		case t.re != nil:
+			score, pos, ok = t.matchRegex(value)
+		case q.Mode == ModeFuzzy && t.Field == "":
+			score, pos, ok = fuzzy(t.Value, value, t.Sensitive)
+		default:
+			score, pos, ok = substring(t.Value, value, t.Sensitive)
+		}
+
+		if ok && (!matched || score > best) {
+			best, positions, matched = score, pos, true
+		}
+	}
+
+	return best, positions, matched
+}
+
+func (t Term) equal(value string) bool {
+	if t.Sensitive {
+		return value == t.Value
+	}
+	return strings.EqualFold(value, t.Value)
+}
+
+// matchRegex scores a regular expression match like a substring match of
+// the same length.
+func (t Term) matchRegex(value string) (int, []int, bool) {
+	loc := t.re.FindStringIndex(value)
+	if loc == nil {
+		return 0, nil, false
+	}
+
+	start := utf8.RuneCountInString(value[:loc[0]])
+	length := utf8.RuneCountInString(value[loc[0]:loc[1]])
+
+	positions := make([]int, length)
+	for k := range positions {
+		positions[k] = start + k
+	}
+
+	return (scoreMatch + bonusConsecutive) * length, positions, true
+}
+
+// Sensitive reports whether any term of the query matches case
+// sensitively.
+func (q Query) Sensitive() bool {
+	for _, group := range q.groups {
+		for _, term := range group {
+			if term.Sensitive {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Matches returns the rune indexes of the characters of text matched by the
+// query's terms for field, or by its unqualified terms, for highlighting.
+func (q Query) Matches(text string, field string) []int {
+	positions := []int{}
+
+	for _, group := range q.groups {
+		for _, term := range group {
+			if term.Negate || (term.Field != "" && term.Field != field) {
+				continue
+			}
+			if _, pos, ok := q.match(term, []string{text}); ok {
+				positions = append(positions, pos...)
+			}
+		}
+	}
+
+	sort.Ints(positions)
+	return slices.Compact(positions)
+}
+
+// Rank keeps the items matching query. Fuzzy queries order them by how well
+// they match, best first.
+func Rank[T any](items []T, query Query, subject func(T) Subject) []T {
+	type scored struct {
+		item  T
+		score int
+	}
+
+	matches := []scored{}
+	for _, item := range items {
+		if score, ok := query.Score(subject(item)); ok {
+			matches = append(matches, scored{item, score})
+		}
+	}
+
+	if query.Mode == ModeFuzzy {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].score > matches[j].score
+		})
+	}
+
+	result := make([]T, len(matches))
+	for i, match := range matches {
+		result[i] = match.item
+	}
+
+	return result
+}
+
+// Empty reports whether the query has no terms and so matches everything.
+func (q Query) Empty() bool {
+	return len(q.groups) == 0
+}
+
+func (q Query) String() string {
+	return q.Text
+}
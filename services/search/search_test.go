@@ -0,0 +1,186 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+var testFields = []string{"name", "health", "label", "image"}
+
+// describe renders the groups of q as "field=value" terms, with ! for
+// negated terms, ^ for case sensitive ones and | between the groups.
+func describe(q Query) string {
+	text := ""
+	for i, group := range q.groups {
+		if i > 0 {
+			text += " | "
+		}
+		for j, term := range group {
+			if j > 0 {
+				text += " "
+			}
+			if term.Negate {
+				text += "!"
+			}
+			if term.Sensitive {
+				text += "^"
+			}
+			text += fmt.Sprintf("%s=%s", term.Field, term.Value)
+		}
+	}
+	return text
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		mode Mode
+		want string
+	}{
+		{"", ModeSubstring, ""},
+		{"nginx", ModeSubstring, "=nginx"},
+		{"health:degraded", ModeSubstring, "health=degraded"},
+		{"Health:degraded", ModeSubstring, "health=degraded"},
+		{"health:Degraded", ModeSubstring, "^health=Degraded"},
+		{"!nginx", ModeSubstring, "!=nginx"},
+		{"!label:team=core", ModeSubstring, "!label=team=core"},
+		{"a b", ModeSubstring, "=a =b"},
+		{"a AND b", ModeSubstring, "=a =b"},
+		{"a && b", ModeSubstring, "=a =b"},
+		{"a OR b c", ModeSubstring, "=a | =b =c"},
+		{"a || b", ModeSubstring, "=a | =b"},
+		{"NGINX", ModeSubstring, "^=NGINX"},
+		{"~pgsql", ModeFuzzy, "=pgsql"},
+		{"~name:pay", ModeFuzzy, "name=pay"},
+		{`re:^pay.*\d$`, ModeRegex, `=^pay.*\d$`},
+		{`re:\S+`, ModeRegex, `=\S+`},
+		{`re:\SX`, ModeRegex, `^=\SX`},
+		{"re:name:^pay", ModeRegex, "name=^pay"},
+		// only known fields qualify a term
+		{"nginx:1.25", ModeSubstring, "=nginx:1.25"},
+		{":8080", ModeSubstring, "=:8080"},
+		{"!nginx:1.25", ModeSubstring, "!=nginx:1.25"},
+		{"image:nginx:1.25", ModeSubstring, "image=nginx:1.25"},
+		{"kind:Pod", ModeSubstring, "^=kind:Pod"},
+		{"re:(?i:x)", ModeRegex, "=(?i:x)"},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.text, testFields...)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+
+		if q.Mode != tt.mode {
+			t.Errorf("Parse(%q) mode = %q, want %q", tt.text, q.Mode, tt.mode)
+		}
+		if got := describe(q); got != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		text  string
+		token string
+		start int
+	}{
+		{"OR a", "OR", 0},
+		{"a OR", "OR", 2},
+		{"a AND OR b", "AND", 2},
+		{"&& a", "&&", 0},
+		{"a health:", "health:", 2},
+		{"!", "!", 0},
+		{"label:=core", "label:=core", 0},
+		{"re:a (b", "(b", 5},
+		{"~a OR", "OR", 3},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.text, testFields...)
+
+		var parseErr *Error
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, want an *Error", tt.text, err)
+			continue
+		}
+		if parseErr.Token != tt.token || parseErr.Start != tt.start || parseErr.End != tt.start+len(tt.token) {
+			t.Errorf("Parse(%q) error at %q [%d:%d], want %q at %d", tt.text, parseErr.Token, parseErr.Start, parseErr.End, tt.token, tt.start)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	subject := func(field string) []string {
+		values := map[string][]string{
+			"name":   {"payments-api"},
+			"health": {"Degraded"},
+			"label":  {"team=core", "tier"},
+			"image":  {"nginx:1.25", "registry:5000/app:v2"},
+		}
+		if field == "" {
+			all := []string{}
+			for _, name := range testFields {
+				all = append(all, values[name]...)
+			}
+			return all
+		}
+		return values[field]
+	}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"", true},
+		{"payments", true},
+		{"PAYMENTS", false},
+		{"health:degraded", true},
+		{"health:Healthy", false},
+		{"!health:degraded", false},
+		{"label:team", true},
+		{"label:team=core", true},
+		{"label:team=co", false},
+		{"label:tier", true},
+		{"nginx:1.25", true},
+		{"nginx:1.26", false},
+		{":5000", true},
+		{"image:app:v2", true},
+		{"health:Healthy OR name:pay", true},
+		{"health:Healthy AND name:pay", false},
+		{"~pmtapi", true},
+		{"re:^pay.*api$", true},
+		{`re:name:^api`, false},
+		{`re:image:\d+\.\d+$`, true},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.text, testFields...)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+
+		if got := q.Match(subject); got != tt.want {
+			t.Errorf("%q matched %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	q, err := Parse("nginx:1 !api name:pay", testFields...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := q.Matches("nginx:1.25", "image"), []int{0, 1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("image matches = %v, want %v", got, want)
+	}
+	if got, want := q.Matches("payments-api", "name"), []int{0, 1, 2}; !slices.Equal(got, want) {
+		t.Errorf("name matches = %v, want %v", got, want)
+	}
+}
@@ -5,35 +5,73 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
 
-var tviewTagRegex = regexp.MustCompile(`\[.*?\]`)
+// tviewTagRegex matches tview's escaped brackets like [red[] first, then
+// style tags like [red], [#1e1e2e:-:bu] or [::U] and region tags like ["a"].
+var tviewTagRegex = regexp.MustCompile(`\[[^\[\]]*\[\]|\[(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?(?::(?:[a-zA-Z]+|#[0-9a-fA-F]{6}|-)?(?::[a-zA-Z]*|:-)?)?\]|\["[^"]*"\]`)
 
+// StripTags removes the tview tags from text and unescapes escaped
+// brackets, which leaves the text as it is shown.
 func StripTags(text string) string {
-	return tviewTagRegex.ReplaceAllString(text, "")
+	return tviewTagRegex.ReplaceAllStringFunc(text, func(tag string) string {
+		if tag == "[]" {
+			return tag
+		}
+		if escaped, ok := strings.CutSuffix(tag, "[]"); ok && escaped != "" {
+			return escaped + "]"
+		}
+		return ""
+	})
 }
 
+// GetTag returns the first style tag of text, or an empty string.
 func GetTag(text string) string {
-	return tviewTagRegex.FindString(text)
+	for _, tag := range tviewTagRegex.FindAllString(text, -1) {
+		if !strings.HasSuffix(tag, "[]") && !strings.HasPrefix(tag, `["`) {
+			return tag
+		}
+	}
+	return ""
 }
 
+// HexToColor parses hexStr and returns def when it is empty or invalid.
 func HexToColor(hexStr string, def tcell.Color) tcell.Color {
 	if hexStr == "" {
 		return def
 	}
 
+	color, err := ParseColor(hexStr)
+	if err != nil {
+		return def
+	}
+
+	return color
+}
+
+// ParseColor accepts hex values like #1e1e2e, the color names known to tcell
+// like red or darkslategray, and default for the terminal's own color.
+func ParseColor(hexStr string) (tcell.Color, error) {
+	name := strings.ToLower(strings.TrimSpace(hexStr))
+	if name == "default" {
+		return tcell.ColorDefault, nil
+	}
+
+	if color, ok := tcell.ColorNames[name]; ok {
+		return color, nil
+	}
+
 	hex := strings.TrimPrefix(hexStr, "#")
 
 	v, err := strconv.ParseInt(hex, 16, 32)
-	if err != nil {
-		fmt.Printf("Error converting hex color %s: %v. Returning ColorDefault.\n", hexStr, err)
-		return tcell.ColorDefault
+	if err != nil || len(hex) != 6 {
+		return tcell.ColorDefault, fmt.Errorf("invalid color %q, expected a hex value like #1e1e2e or a color name like red", hexStr)
 	}
 
-	userColor := tcell.NewHexColor(int32(v))
-	return userColor
+	return tcell.NewHexColor(int32(v)), nil
 }
 
 func GetContrastColor(c tcell.Color) tcell.Color {
@@ -46,3 +84,17 @@ func GetContrastColor(c tcell.Color) tcell.Color {
 	}
 	return tcell.ColorWhite
 }
+
+// ShortDuration formats d coarsely, like 42s, 5m or 3h, or 2d past a day.
+func ShortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}